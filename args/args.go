@@ -4,6 +4,8 @@ import (
 	"errors"
 	"net/url"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 
 	flag "github.com/spf13/pflag"
@@ -29,6 +31,10 @@ type PipelineArgs struct {
 	// In Dagger / CLI clients, this will likely be populated by a random UUID if not provided.
 	BuildID string
 
+	// BuildIDProvided is true if the BuildID was provided with the '-build-id' argument instead of being randomly generated.
+	// Filesystem states are only namespaced by the BuildID if it was provided, so that local runs keep using the same state file.
+	BuildIDProvided bool
+
 	// CanStdinPrompt is true if the pipeline can prompt for absent arguments via stdin
 	CanStdinPrompt bool
 
@@ -36,6 +42,19 @@ type PipelineArgs struct {
 	// Example usage: `-arg={key}={value}
 	ArgMap ArgMap
 
+	// ArgEnvPrefix is the prefix of the environment variables that arguments are read from when they are not in the ArgMap.
+	// For example, with the default prefix 'SCRIBE_ARG_', the 'git-branch' argument is read from 'SCRIBE_ARG_GIT_BRANCH'.
+	// If it is empty, then arguments are not read from environment variables.
+	ArgEnvPrefix string
+
+	// ArgEnv maps the keys of arguments to the environment variables that they should be read from instead, without the ArgEnvPrefix.
+	// Example usage: `-arg-env=git-branch=DRONE_BRANCH`
+	ArgEnv ArgMap
+
+	// ArgFile is the path to a file that arguments are read from when they are not in the ArgMap or the environment.
+	// If the file has a '.yml' or '.yaml' extension, then it is a YAML object of argument keys and values. Otherwise it is a '.env' file whose variables are named like the environment variables.
+	ArgFile string
+
 	// LogLevel defines how detailed the output logs in the pipeline should be.
 	// Possible options are [debug, info, warn, error].
 	// The default value is warn.
@@ -43,20 +62,58 @@ type PipelineArgs struct {
 
 	// State is a URL where the build state is stored.
 	// Examples:
-	// * 'fs:///var/scribe/state.json' - Uses a JSON file to store the state.
+	// * 'fs:///var/scribe/state.json' - Uses a JSON file to store the state. If the BuildID was provided, then the file is placed in a folder named after it, like '/var/scribe/{build-id}/state.json'.
 	// * 'fs:///c:/scribe/state.json' - Uses a JSON file to store the state, but on Windows.
-	// * 'fs:///var/scribe/state/' - Stores the state file in the given directory, using a randomly generated ID to store the state.
+	// * 'fs:///var/scribe/state/' - Stores the state file in the given directory, using the BuildID as the name of the state file if it was provided, or a random name if it was not.
 	//    * This might be a good option if implementing a Scribe client in a provider.
-	// * 's3://bucket-name/path'
-	// * 'gcs://bucket-name/path'
+	// * 's3://bucket-name/path' - Stores the state in an S3 bucket. Credentials are read from the 'AWS_ACCESS_KEY_ID' and 'AWS_SECRET_ACCESS_KEY' environment variables.
+	//    * The 'region' and 'endpoint' query parameters can be used with S3-compatible services, like 's3://bucket-name/path?endpoint=http://localhost:9000'.
+	// * 'gcs://bucket-name/path' - Stores the state in a Google Cloud Storage bucket, using the token of the default service account or the 'GOOGLE_OAUTH_ACCESS_TOKEN' environment variable.
+	//    * The 'endpoint' query parameter or the 'STORAGE_EMULATOR_HOST' environment variable can be used with a local fake GCS server.
+	// * 'http://localhost:8080' - Stores the state in a state server, like one started with 'scribe state serve'.
 	// If 'State' is not provided, then one is created using os.Tmpdir.
 	State string
 
+	// StateKeyFile is the path to a file that contains the key used to encrypt secrets in the state.
+	// The key can also be provided in the 'SCRIBE_STATE_KEY' environment variable. If there is no key, then secrets are stored in plaintext.
+	StateKeyFile string
+
+	// Cache is a URL where the outputs of cached steps are stored in between runs.
+	// Examples:
+	// * 'file:///var/scribe/cache' - Stores cached step outputs in the given directory.
+	// If 'Cache' is not provided, then a directory in os.Tmpdir is used. If it is provided but empty, then caching is disabled.
+	Cache string
+
 	// PipelineName can be provided in a multi-pipeline setup to run an entire pipeline rather than the entire suite of pipelines.
 	PipelineName []string
 
 	// Event can be provided in a multi-pipeline setup locally to simulate an event.
 	Event string
+
+	// ContinueOnError defines what happens when a step or pipeline fails while others run in parallel with it.
+	// If it is false, which is the default, then the others are cancelled and the first error is returned.
+	// If it is true, then the others run to completion and every error is returned.
+	ContinueOnError bool
+
+	// Parallelism is the total weight of the steps that can run at the same time on this machine. Each step has a weight of 1, unless it is set with Step.WithCPU.
+	// It defaults to the number of CPUs. If it is 0, then there is no limit.
+	Parallelism int
+
+	// DroneMode defines how the Drone client converts a pipeline into Drone steps.
+	// * 'pipeline' - Every pipeline is ran as a single Drone step. This is the default.
+	// * 'step' - Every step in the pipeline is converted into its own Drone step that uses the step's image.
+	DroneMode string
+
+	// DroneLanguage defines the language of the config that the Drone client generates.
+	// * 'yaml' - Generates a '.drone.yml' file. This is the default.
+	// * 'starlark' - Generates a '.drone.star' file.
+	DroneLanguage string
+
+	// GraphFormat defines the format of the document that the graph client writes.
+	// * 'dot' - A Graphviz DOT document. This is the default.
+	// * 'mermaid' - A Mermaid flowchart.
+	// * 'json' - A JSON document.
+	GraphFormat string
 }
 
 type pipelineNames struct {
@@ -83,6 +140,11 @@ func ParseArguments(args []string) (*PipelineArgs, error) {
 		Path:   os.TempDir(),
 	}
 
+	var defaultCache = &url.URL{
+		Scheme: "file",
+		Path:   filepath.Join(os.TempDir(), "scribe-cache"),
+	}
+
 	var (
 		flagSet       = flag.NewFlagSet("run", flag.ContinueOnError)
 		client        string
@@ -92,25 +154,45 @@ func ParseArguments(args []string) (*PipelineArgs, error) {
 		version       string
 		buildID       string
 		noStdinPrompt bool
+		continueOnErr bool
+		parallelism   int
 		argMap        = ArgMap(map[string]string{})
+		argEnvPrefix  string
+		argEnv        = ArgMap(map[string]string{})
+		argFile       string
 		state         string
+		stateKeyFile  string
+		cache         string
 		event         string
 		pipelineName  pipelineNames
+		droneMode     string
+		droneLanguage string
+		graphFormat   string
 	)
 
 	// Flags with shorthand options
-	flagSet.StringVarP(&client, "client", "c", "dagger", "dagger|drone. Default: dagger")
+	flagSet.StringVarP(&client, "client", "c", "dagger", "dagger|cli|drone|github|gitlab|graph. Default: dagger")
 	flagSet.StringVarP(&logLevel, "log-level", "l", "info", "The level of detail in the pipeline's log output. Default: 'warn'. Options: [trace, debug, info, warn, error]")
 	flagSet.StringVarP(&buildID, "build-id", "b", stringutil.Random(12), "A unique identifier typically assigned by a build system. Defaults to a random string if no build ID is provided")
-	flagSet.StringVarP(&state, "state", "s", defaultState.String(), "A URI that refers to a state file or directory where state between steps is stored. Must include a protocol, like 'file://', 'gcs://', or 's3://'")
-	flagSet.StringVarP(&event, "event", "e", "git-commit", "The name of an event to run. The default behavior is to run all pipelines that do not have a source event")
+	flagSet.StringVarP(&state, "state", "s", defaultState.String(), "A URI that refers to a state file or directory where state between steps is stored. Must include a protocol, like 'file://', 'gcs://', 's3://', or 'http://'. If '-build-id' is provided, then a 'file://' state is placed in a folder named after it, like 'file:///var/scribe/{build-id}/state.json'")
+	flagSet.StringVarP(&event, "event", "e", "git-commit", "The name of an event to simulate when running locally. Options: [git-commit, git-tag, pull-request, cron, promote, manual]. Only pipelines that run on the event are ran")
 	flagSet.VarP(&pipelineName, "pipeline", "p", "A pipeline name, giving a value for this flag will result in only the pipeline of the specified name being executed. The default empty string will run all pipelines.")
 
+	flagSet.StringVar(&stateKeyFile, "state-key-file", "", "A file that contains the key used to encrypt secrets in the state. The key can also be provided in the 'SCRIBE_STATE_KEY' environment variable. Secrets are stored in plaintext if there is no key. Generated CI configs do not include this path; they read the key from a CI secret named 'SCRIBE_STATE_KEY'")
+	flagSet.StringVar(&cache, "cache", defaultCache.String(), "A URI that refers to a directory where the outputs of cached steps are stored. Must include a protocol, like 'file://'. Provide an empty value to disable caching")
 	flagSet.Var(&step, "step", "A number that defines what specific step to run")
 	flagSet.Var(&argMap, "arg", "Provide pre-available arguments for use in pipeline steps. This argument can be provided multiple times. Format: '-arg={key}={value}")
+	flagSet.StringVar(&argEnvPrefix, "arg-env-prefix", "SCRIBE_ARG_", "The prefix of the environment variables that arguments are read from if they are not provided with '-arg'. Provide an empty value to not read arguments from the environment")
+	flagSet.Var(&argEnv, "arg-env", "Read an argument from an environment variable with a different name. This argument can be provided multiple times. Format: '-arg-env={key}={ENV_VAR}'")
+	flagSet.StringVar(&argFile, "arg-file", "", "A '.env' or YAML ('.yml', '.yaml') file that arguments are read from if they are not provided with '-arg' or in the environment")
 	flagSet.BoolVar(&noStdinPrompt, "no-stdin", false, "If this flag is provided, then the CLI pipeline will not request absent arguments via stdin")
+	flagSet.BoolVar(&continueOnErr, "continue-on-error", false, "If this flag is provided, then steps and pipelines that run in parallel with one that fails are not cancelled, and every error is reported once they are all done")
+	flagSet.IntVar(&parallelism, "parallelism", runtime.NumCPU(), "The total weight of the steps that can run at the same time when running locally. Each step has a weight of 1 unless it has a CPU weight. Defaults to the number of CPUs; 0 disables the limit")
 	flagSet.StringVar(&pathOverride, "path", "", "Providing the path argument overrides the $PWD of the pipeline for generation")
 	flagSet.StringVar(&version, "version", "latest", "The version is provided by the 'scribe' command, however if only using 'go run', it can be provided here")
+	flagSet.StringVar(&droneLanguage, "drone-language", "yaml", "yaml|starlark. Defines whether the Drone client generates a '.drone.yml' or a '.drone.star' file")
+	flagSet.StringVar(&graphFormat, "graph-format", "dot", "dot|mermaid|json. Defines the format of the document that the graph client writes")
+	flagSet.StringVar(&droneMode, "drone-mode", "pipeline", "pipeline|step. Defines whether the Drone client runs every pipeline as a single Drone step, or converts every step into its own Drone step")
 
 	if err := flagSet.Parse(args); err != nil {
 		return nil, err
@@ -126,15 +208,30 @@ func ParseArguments(args []string) (*PipelineArgs, error) {
 		return nil, errors.New("both '-step' and '-pipeline' (-p) can not be provided at the same time")
 	}
 
+	if parallelism < 0 {
+		return nil, errors.New("'-parallelism' can not be less than 0")
+	}
+
 	arguments := &PipelineArgs{
-		CanStdinPrompt: !noStdinPrompt,
-		Client:         client,
-		Version:        version,
-		LogLevel:       level,
-		BuildID:        buildID,
-		State:          state,
-		PipelineName:   pipelineName.names,
-		Event:          event,
+		CanStdinPrompt:  !noStdinPrompt,
+		Client:          client,
+		Version:         version,
+		LogLevel:        level,
+		BuildID:         buildID,
+		BuildIDProvided: flagSet.Changed("build-id"),
+		State:           state,
+		StateKeyFile:    stateKeyFile,
+		Cache:           cache,
+		PipelineName:    pipelineName.names,
+		Event:           event,
+		ContinueOnError: continueOnErr,
+		Parallelism:     parallelism,
+		DroneMode:       droneMode,
+		DroneLanguage:   droneLanguage,
+		GraphFormat:     graphFormat,
+		ArgEnvPrefix:    argEnvPrefix,
+		ArgEnv:          argEnv,
+		ArgFile:         argFile,
 	}
 
 	if step.Valid {
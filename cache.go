@@ -0,0 +1,36 @@
+package scribe
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/grafana/scribe/cache"
+)
+
+func newLocalCache(u *url.URL) (cache.Store, error) {
+	return cache.NewLocalStore(u.Path)
+}
+
+var caches = map[string]func(*url.URL) (cache.Store, error){
+	"file": newLocalCache,
+	"fs":   newLocalCache,
+}
+
+// GetCache returns the cache store for the '-cache' argument.
+// If the value is empty, then caching is disabled and a nil store is returned.
+func GetCache(val string) (cache.Store, error) {
+	if val == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(val)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := caches[u.Scheme]; ok {
+		return v(u)
+	}
+
+	return nil, fmt.Errorf("cache URL scheme '%s' not recognized", val)
+}
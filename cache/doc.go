@@ -0,0 +1,3 @@
+// Package cache defines where cached step outputs are stored between pipeline runs.
+// A Store is selected using the '-cache' argument, and is used by cached actions (see pipeline.CacheAction) to skip steps whose inputs have not changed.
+package cache
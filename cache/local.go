@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore stores cache entries as files in a directory on the local filesystem.
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return nil, fmt.Errorf("error creating cache directory '%s': %w", dir, err)
+	}
+
+	return &LocalStore{
+		dir: dir,
+	}, nil
+}
+
+func (l *LocalStore) path(key string) string {
+	return filepath.Join(l.dir, key)
+}
+
+func (l *LocalStore) Exists(key string) (bool, error) {
+	if _, err := os.Stat(l.path(key)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (l *LocalStore) Get(key string) (io.ReadCloser, error) {
+	f, err := os.Open(l.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w. key: %s", ErrorNotFound, key)
+		}
+
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Put writes the entry to a temporary file first and then renames it so that a partially written entry is never read.
+func (l *LocalStore) Put(key string, r io.Reader) error {
+	tmp, err := os.CreateTemp(l.dir, fmt.Sprintf(".%s-*", key))
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), l.path(key))
+}
@@ -0,0 +1,49 @@
+package cache_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/grafana/scribe/cache"
+	"github.com/grafana/scribe/testutil"
+)
+
+func TestLocalStore(t *testing.T) {
+	t.Run("Get should return the value stored with Put", func(t *testing.T) {
+		store, err := cache.NewLocalStore(t.TempDir())
+		testutil.EnsureError(t, err, nil)
+
+		testutil.EnsureError(t, store.Put("example", bytes.NewBufferString("example value")), nil)
+
+		exists, err := store.Exists("example")
+		testutil.EnsureError(t, err, nil)
+		if !exists {
+			t.Fatal("Expected key 'example' to exist after Put")
+		}
+
+		r, err := store.Get("example")
+		testutil.EnsureError(t, err, nil)
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		testutil.EnsureError(t, err, nil)
+		if string(b) != "example value" {
+			t.Fatalf("Unexpected value from cache. Expected '%s', received '%s'", "example value", string(b))
+		}
+	})
+
+	t.Run("Get should return ErrorNotFound for keys that were not stored", func(t *testing.T) {
+		store, err := cache.NewLocalStore(t.TempDir())
+		testutil.EnsureError(t, err, nil)
+
+		exists, err := store.Exists("missing")
+		testutil.EnsureError(t, err, nil)
+		if exists {
+			t.Fatal("Expected key 'missing' to not exist")
+		}
+
+		_, err = store.Get("missing")
+		testutil.EnsureError(t, err, cache.ErrorNotFound)
+	})
+}
@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	ErrorNotFound = errors.New("key not found in cache")
+)
+
+// Store stores and retrieves cache entries by key.
+// Keys are generated by the pipeline package and are safe to use as file or object names.
+type Store interface {
+	// Exists returns true if an entry with the provided key is in the cache.
+	Exists(key string) (bool, error)
+
+	// Get returns the contents of the entry with the provided key.
+	// If there is no entry for the key, then an error that wraps ErrorNotFound is returned.
+	// The caller is responsible for closing the returned ReadCloser.
+	Get(key string) (io.ReadCloser, error)
+
+	// Put stores the contents of the reader as the entry with the provided key, replacing any existing entry.
+	Put(key string, r io.Reader) error
+}
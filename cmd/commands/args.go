@@ -3,7 +3,12 @@ package commands
 import "github.com/grafana/scribe/args"
 
 // MustParseRunArgs parses the "run" arguments from the args slice. These options are provided by the scribe command and are typically not user-specified
+// If the first argument is 'graph', like in 'scribe graph --graph-format=mermaid ./ci', then the pipeline is ran with the graph client, which writes the structure of the pipeline instead of running it.
 func MustParseArgs(pargs []string) *args.PipelineArgs {
+	if len(pargs) != 0 && pargs[0] == "graph" {
+		pargs = append([]string{"--client", "graph"}, pargs[1:]...)
+	}
+
 	v, err := args.ParseArguments(pargs)
 	if err != nil {
 		panic(err)
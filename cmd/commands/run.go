@@ -77,12 +77,53 @@ func Run(ctx context.Context, opts *RunOpts) *exec.Cmd {
 	// But it's important to note that a lot happens before it actually reaches the pipeline code and produces a command like this:
 	//   /tmp/random-string -client drone -path ./demo/basic
 	// So the path to the pipeline is not preserved, which is why we have to provide the path as an argument
-	cmdArgs := []string{"run", path, "--client", args.Client, "--log-level", args.LogLevel.String(), "--path", args.Path, "--version", version, "--build-id", args.BuildID, "--event", args.Event}
+	cmdArgs := []string{"run", path, "--client", args.Client, "--log-level", args.LogLevel.String(), "--path", args.Path, "--version", version, "--event", args.Event}
+
+	// The build ID is only forwarded if it was provided, as the pipeline only namespaces its state when it is.
+	if args.BuildIDProvided {
+		cmdArgs = append(cmdArgs, "--build-id", args.BuildID)
+	}
+
+	if args.Cache != "" {
+		cmdArgs = append(cmdArgs, "--cache", args.Cache)
+	}
+
+	if args.StateKeyFile != "" {
+		cmdArgs = append(cmdArgs, "--state-key-file", args.StateKeyFile)
+	}
+
+	if args.ContinueOnError {
+		cmdArgs = append(cmdArgs, "--continue-on-error")
+	}
+
+	cmdArgs = append(cmdArgs, "--parallelism", strconv.Itoa(args.Parallelism))
+
+	if args.DroneMode != "" {
+		cmdArgs = append(cmdArgs, "--drone-mode", args.DroneMode)
+	}
+
+	if args.DroneLanguage != "" {
+		cmdArgs = append(cmdArgs, "--drone-language", args.DroneLanguage)
+	}
+
+	if args.GraphFormat != "" {
+		cmdArgs = append(cmdArgs, "--graph-format", args.GraphFormat)
+	}
 
 	for k, v := range args.ArgMap {
 		cmdArgs = append(cmdArgs, "--arg", fmt.Sprintf("%s=%s", k, v))
 	}
 
+	cmdArgs = append(cmdArgs, "--arg-env-prefix", args.ArgEnvPrefix)
+
+	for k, v := range args.ArgEnv {
+		cmdArgs = append(cmdArgs, "--arg-env", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	if args.ArgFile != "" {
+		cmdArgs = append(cmdArgs, "--arg-file", args.ArgFile)
+	}
+
 	if args.PipelineName != nil {
 		for _, v := range args.PipelineName {
 			cmdArgs = append(cmdArgs, fmt.Sprintf("--pipeline=\"%s\"", v))
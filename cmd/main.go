@@ -42,6 +42,14 @@ func main() {
 		ctx = context.Background()
 	)
 
+	if len(os.Args) > 2 && os.Args[1] == "state" && os.Args[2] == "serve" {
+		if err := stateServe(ctx, log, os.Args[3:]); err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	args := commands.MustParseArgs(os.Args[1:])
 
 	cmd := commands.Run(ctx, &commands.RunOpts{
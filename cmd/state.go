@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/scribe"
+	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/cmdutil"
+	"github.com/grafana/scribe/state"
+	"github.com/sirupsen/logrus"
+	flag "github.com/spf13/pflag"
+)
+
+// stateServe handles the "scribe state serve" command, which starts a state server that stores the state of pipelines in another state, like a local file or a bucket.
+// Pipelines use the state server by providing its URL in the '--state' argument, like '--state=http://localhost:8080'.
+func stateServe(ctx context.Context, log *logrus.Logger, pargs []string) error {
+	defaultState := &url.URL{
+		Scheme: "file",
+		Path:   filepath.Join(os.TempDir(), "scribe-state-server.json"),
+	}
+
+	var (
+		flagSet  = flag.NewFlagSet("state serve", flag.ContinueOnError)
+		addr     string
+		stateURL string
+		dir      string
+		logLevel string
+	)
+
+	flagSet.StringVarP(&addr, "addr", "a", ":8080", "The address that the state server listens on")
+	flagSet.StringVarP(&stateURL, "state", "s", defaultState.String(), "A URI that refers to the state where the values, files, and directories sent to the server are stored. Must include a protocol, like 'file://', 'gcs://', or 's3://'")
+	flagSet.StringVarP(&dir, "dir", "d", "", "The folder where uploaded directories are extracted before they are stored. Defaults to a new temporary folder")
+	flagSet.StringVarP(&logLevel, "log-level", "l", "info", "The level of detail in the server's log output. Options: [trace, debug, info, warn, error]")
+
+	if err := flagSet.Parse(pargs); err != nil {
+		return err
+	}
+
+	level, err := logrus.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(level)
+
+	// The server should only store what it receives, so it should never read arguments from the command line or stdin.
+	s, err := scribe.GetState(stateURL, log, &args.PipelineArgs{})
+	if err != nil {
+		return fmt.Errorf("error initializing state: %w", err)
+	}
+
+	handler, err := state.NewServer(s.Handler, dir, log.WithField("server", "state"))
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+
+	go func() {
+		c := make(chan os.Signal, 1)
+		cmdutil.NotifySignals(c)
+		select {
+		case sig := <-c:
+			log.Debugln("Received OS signal", sig.String())
+		case <-ctx.Done():
+		}
+		server.Shutdown(context.Background())
+	}()
+
+	log.Infof("Serving state '%s' on '%s'", stateURL, addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package cmdutil
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/grafana/scribe/args"
 	"github.com/grafana/scribe/pipeline"
@@ -16,6 +17,22 @@ type CommandOpts struct {
 	CompiledPipeline string
 }
 
+// argMapFlags returns the '--arg' flags for every key in the ArgMap, sorted by key so that generated commands are consistent.
+func argMapFlags(m args.ArgMap) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	flags := make([]string, len(keys))
+	for i, k := range keys {
+		flags[i] = fmt.Sprintf("--arg=%s=%s", k, m[k])
+	}
+
+	return flags
+}
+
 // StepCommand returns the command string for running a single step.
 // The path argument can be omitted, which is particularly helpful if the current directory is a pipeline.
 func StepCommand(opts CommandOpts) ([]string, error) {
@@ -29,6 +46,10 @@ func StepCommand(opts CommandOpts) ([]string, error) {
 		args = append(args, fmt.Sprintf("--state=%s", opts.State))
 	}
 
+	if opts.Cache != "" {
+		args = append(args, fmt.Sprintf("--cache=%s", opts.Cache))
+	}
+
 	if opts.LogLevel != 0 {
 		args = append(args, fmt.Sprintf("--log-level=%s", opts.LogLevel.String()))
 	}
@@ -37,11 +58,7 @@ func StepCommand(opts CommandOpts) ([]string, error) {
 		args = append(args, fmt.Sprintf("--version=%s", opts.Version))
 	}
 
-	if len(opts.ArgMap) != 0 {
-		for k, v := range opts.ArgMap {
-			args = append(args, fmt.Sprintf("--arg=%s=%s", k, v))
-		}
-	}
+	args = append(args, argMapFlags(opts.ArgMap)...)
 
 	name := "scribe"
 
@@ -73,6 +90,10 @@ func PipelineCommand(opts PipelineCommandOpts) ([]string, error) {
 		args = append(args, fmt.Sprintf("--state=%s", opts.State))
 	}
 
+	if opts.Cache != "" {
+		args = append(args, fmt.Sprintf("--cache=%s", opts.Cache))
+	}
+
 	if opts.LogLevel != 0 {
 		args = append(args, fmt.Sprintf("--log-level=%s", opts.LogLevel))
 	}
@@ -85,11 +106,7 @@ func PipelineCommand(opts PipelineCommandOpts) ([]string, error) {
 		args = append(args, fmt.Sprintf("--event=%s", opts.Event))
 	}
 
-	if len(opts.ArgMap) != 0 {
-		for k, v := range opts.ArgMap {
-			args = append(args, fmt.Sprintf("--arg=%s=%s", k, v))
-		}
-	}
+	args = append(args, argMapFlags(opts.ArgMap)...)
 
 	name := "scribe"
 
@@ -63,7 +63,7 @@ func main() {
 	sw.Run(
 		pipeline.NamedStep("compile backend", makefile.Target("build")).WithImage("alpine:latest"),
 		pipeline.NamedStep("compile frontend", makefile.Target("package")).WithImage("alpine:latest"),
-		pipeline.NamedStep("build docker image", makefile.Target("build")).Requires(pipeline.ArgumentDockerSocketFS).WithImage("alpine:latest"),
+		pipeline.NamedStep("build docker image", makefile.Target("build")).Requires(pipeline.ArgumentDockerSocketFS).WithImage("alpine:latest").WithLock("docker"),
 	)
 
 	sw.Run(
@@ -72,4 +72,9 @@ func main() {
 				state.NewSecretArgument("gcs-publish-key"),
 			),
 	)
+
+	// If any of the steps above fail, then notify the team.
+	sw.Run(
+		pipeline.NamedStep("notify failure", makefile.Target("notify")).WithImage("alpine:latest").OnFailure(),
+	)
 }
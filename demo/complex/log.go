@@ -28,6 +28,14 @@ func NoOpAction(name string, duration time.Duration) pipeline.Action {
 	}
 }
 
+// CollectMetrics runs in the background until the rest of the pipeline is done.
+func CollectMetrics() pipeline.Action {
+	return func(ctx context.Context, opts pipeline.ActionOpts) error {
+		<-ctx.Done()
+		return nil
+	}
+}
+
 func IntegrationTest(variant string, duration time.Duration) pipeline.Action {
 	return func(ctx context.Context, opts pipeline.ActionOpts) error {
 		d := int64(duration.Seconds()) / 2
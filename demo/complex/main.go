@@ -12,6 +12,7 @@ func main() {
 	defer sw.Done()
 
 	sw.Background(pipeline.NamedStep("redis", pipeline.DefaultAction).WithImage("redis:6"))
+	sw.Background(pipeline.NamedStep("collect metrics", CollectMetrics()).WithImage("alpine:3"))
 
 	sw.Run(pipeline.NamedStep("initalize", NoOpAction("initialize", time.Second*22)))
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/scribe"
 	"github.com/grafana/scribe/fs"
@@ -61,15 +62,21 @@ func publishPipeline(sw *scribe.Scribe) {
 		}),
 	)
 
+	// Every step in this pipeline must finish within 30 minutes.
+	sw.DefaultTimeout(30 * time.Minute)
+
 	installDependencies(sw)
 
 	sw.Parallel(
-		pipeline.NamedStep("compile backend", makefile.Target("build")),
+		// Compiling the backend keeps every CPU busy, so fewer steps run next to it when running locally.
+		pipeline.NamedStep("compile backend", makefile.Target("build")).WithCPU(4),
 		pipeline.NamedStep("compile frontend", makefile.Target("package")),
 	)
 
 	sw.Run(
-		pipeline.NamedStep("publish", makefile.Target("publish")).Requires(state.NewSecretArgument("gcp-publish-key")),
+		pipeline.NamedStep("publish", makefile.Target("publish")).
+			Requires(state.NewSecretArgument("gcp-publish-key")).
+			WithRetry(2, 10*time.Second),
 	)
 }
 
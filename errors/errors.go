@@ -3,11 +3,13 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
 	New = errors.New
 	Is  = errors.Is
+	As  = errors.As
 )
 
 // ErrorSkipValidation can be returned in the Client's Validate interface to prevent the error from stopping the pipeline execution
@@ -33,10 +35,56 @@ func NewPipelineError(err string, desc string) *PipelineError {
 	}
 }
 
+// ErrorStack is a list of errors. It is also an error itself, so that every error in the stack can be returned at once.
+// errors.Is and errors.As match the ErrorStack if they match any of the errors in it.
 type ErrorStack struct {
 	Errors []error
 }
 
+func (e *ErrorStack) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	s := make([]string, len(e.Errors))
+	for i, v := range e.Errors {
+		s[i] = v.Error()
+	}
+
+	return fmt.Sprintf("%d errors occurred: %s", len(e.Errors), strings.Join(s, "; "))
+}
+
+// Is returns true if any of the errors in the stack match the target.
+func (e *ErrorStack) Is(target error) bool {
+	for _, v := range e.Errors {
+		if errors.Is(v, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error in the stack that matches the target, and if one is found, sets target to that error value and returns true.
+func (e *ErrorStack) As(target any) bool {
+	for _, v := range e.Errors {
+		if errors.As(v, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Err returns the stack as an error, or nil if the stack is empty.
+func (e *ErrorStack) Err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+
+	return e
+}
+
 func (e *ErrorStack) Push(err error) {
 	e.Errors = append(e.Errors, err)
 }
@@ -7,6 +7,8 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/grafana/scribe/args"
 	"github.com/grafana/scribe/pipeline"
 	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/grafana/scribe/pipeline/clients/cli"
 	"github.com/grafana/scribe/plog"
 	"github.com/grafana/scribe/state"
 	"github.com/opentracing/opentracing-go"
@@ -36,7 +39,13 @@ var ArgDefaults = map[string]func(context.Context) *exec.Cmd{
 		return exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
 	},
 	pipeline.ArgumentTagName.Key: func(ctx context.Context) *exec.Cmd {
-		return exec.CommandContext(ctx, "git", "describe", "--tags")
+		return exec.CommandContext(ctx, "git", "describe", "--tags", "--exact-match")
+	},
+	pipeline.ArgumentSourceBranch.Key: func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
+	},
+	pipeline.ArgumentChangedFiles.Key: func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, "git", "diff-tree", "--no-commit-id", "--name-only", "-r", "HEAD")
 	},
 }
 
@@ -99,6 +108,11 @@ func executeWithSteps(
 			if err != nil {
 				return fmt.Errorf("could not find step with id '%d'. Error: %w", *args.Step, err)
 			}
+			// The dependencies of the step are the steps that ran before it in the original collection, which are not in the new collection.
+			for i := range step {
+				step[i].Dependencies = nil
+			}
+
 			l := pipeline.NewStepList(n.Next(), step...)
 			c, err := pipeline.NewCollectionWithSteps(name, l)
 			if err != nil {
@@ -135,7 +149,84 @@ func executeWithPipelines(
 	}
 }
 
-// executeWithEvent uses the provided '-event' argument which will populate the state with data that represents the event.
+// eventValue returns the value of an argument that the simulated event provides.
+// Values provided with the '-arg' flag are used first, then values that are already in the state, and then the values from the commands in ArgDefaults, like the current git branch.
+func eventValue(ctx context.Context, pargs *args.PipelineArgs, s *state.State, arg state.Argument) (string, bool) {
+	if v, err := pargs.ArgMap.Get(arg.Key); err == nil {
+		return v, true
+	}
+
+	// Only the state handler is checked, because the fallback readers would read the '-arg' flags again or prompt for the value.
+	if arg.Type == state.ArgumentTypeString {
+		if v, err := s.Handler.GetString(arg); err == nil {
+			return v, true
+		}
+	}
+
+	if cmd, ok := ArgDefaults[arg.Key]; ok {
+		v, err := cmd(ctx).Output()
+		if err == nil {
+			return strings.TrimSpace(string(v)), true
+		}
+	}
+
+	return "", false
+}
+
+func setEventValue(s *state.State, arg state.Argument, value string) error {
+	switch arg.Type {
+	case state.ArgumentTypeString, state.ArgumentTypeSecret:
+		return s.SetString(arg, value)
+	case state.ArgumentTypeInt64:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		return s.SetInt64(arg, v)
+	case state.ArgumentTypeFloat64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		return s.SetFloat64(arg, v)
+	case state.ArgumentTypeBool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		return s.SetBool(arg, v)
+	}
+
+	return fmt.Errorf("arguments of type '%s' can not be provided by an event", arg.Type)
+}
+
+// eventValues reads the values of the arguments that the simulated event provides and adds them to the state, so that they are available before the first step runs.
+// The returned map is keyed by argument key and is used to match the filters of the event.
+func eventValues(ctx context.Context, pargs *args.PipelineArgs, s *state.State, log logrus.FieldLogger, provides []state.Argument) map[string]string {
+	values := map[string]string{}
+	for _, arg := range provides {
+		if _, ok := values[arg.Key]; ok {
+			continue
+		}
+
+		value, ok := eventValue(ctx, pargs, s, arg)
+		if !ok {
+			log.Debugf("No value found for event argument '%s'; it can be provided using '-arg=%s={value}'", arg.Key, arg.Key)
+			continue
+		}
+
+		values[arg.Key] = value
+		if err := setEventValue(s, arg, value); err != nil {
+			log.WithError(err).Warnf("Failed to add event argument '%s' to the state", arg.Key)
+		}
+	}
+
+	return values
+}
+
+// executeWithEvent uses the provided '-event' argument to simulate an event locally.
+// The arguments that the event provides are read from the '-arg' flags or from git and are added to the state.
+// Only the pipelines with an event whose filters match those values are ran.
 func executeWithEvent(
 	args *args.PipelineArgs,
 	opts clients.CommonOpts,
@@ -143,31 +234,20 @@ func executeWithEvent(
 ) executeFunc {
 	log := opts.Log
 	return func(ctx context.Context, collection *pipeline.Collection) error {
-		type event struct {
-			Args     []state.Argument
-			Pipeline int64
+		// If specific pipelines or steps were selected, like in the commands of generated CI configs, then the CI service has already handled the event.
+		// The '-event' flag has a default value, so it is cleared to let the clients know that the event is not known when they check the events of steps.
+		if args.Step != nil || len(args.PipelineName) != 0 {
+			args.Event = ""
+			return ef(ctx, collection)
 		}
 
-		var (
-			pipelineList = map[string]event{}
-		)
-
-		// For every pipeline, set the arguments that each event requires into the pipeline.
+		// events is a map of every event in the pipelines to the arguments that it provides.
+		// This gives us a list of possible events that the user could have selected which we can present to them.
+		events := map[string][]state.Argument{}
 		if err := collection.WalkPipelines(ctx, func(ctx context.Context, pipelines ...pipeline.Pipeline) error {
 			for _, v := range pipelines {
-				// By default assume the user has selected the git-commit event
-				pipelineList["git-commit"] = event{
-					Args:     pipeline.GitCommitEventArgs,
-					Pipeline: v.ID,
-				}
-
-				// However, still add every event found in the pipelines. This gives us a list of possible events that the user could have selected which we can present to them.
 				for _, e := range v.Events {
-					// Nailvely add each event to the list. It doesn't matter if we overwrite what's already there because event name collisions shouldn't happen.
-					pipelineList[e.Name] = event{
-						Args:     e.Provides,
-						Pipeline: v.ID,
-					}
+					events[e.Name] = append(events[e.Name], e.Provides...)
 				}
 			}
 			return nil
@@ -175,32 +255,57 @@ func executeWithEvent(
 			return err
 		}
 
-		if len(pipelineList) == 0 {
+		if len(events) == 0 {
 			return ef(ctx, collection)
 		}
 
-		e := opts.Args.Event
+		keys := make([]string, 0, len(events))
+		for k := range events {
+			keys = append(keys, "'"+k+"'")
+		}
+		sort.Strings(keys)
+
+		e := args.Event
 		// If the user has not provided an event argument, then set a default and warn them.
 		if e == "" {
 			log.Warnln("No event was selected; assuming event is 'git-commit'")
-			keys := make([]string, len(pipelineList))
-			i := 0
-			for k := range pipelineList {
-				keys[i] = "'" + k + "'"
-				i++
-			}
-
 			log.Warnln("Other possible events for this program are:", strings.Join(keys, " "))
 			e = "git-commit"
 		}
 
-		pipelines, err := collection.PipelinesByEvent(ctx, e)
-		if err != nil {
-			return fmt.Errorf("error finding pipeline by event '%s': '%w'", e, err)
+		provides, ok := events[e]
+		if !ok {
+			return fmt.Errorf("no pipelines run on event '%s'. Possible events are: %s", e, strings.Join(keys, " "))
+		}
+
+		// The clients check the events of steps that use Step.WhenEvent against the selected event, including the default.
+		args.Event = e
+
+		values := eventValues(ctx, args, opts.State, log, provides)
+
+		selected := []pipeline.Pipeline{}
+		if err := collection.WalkPipelines(ctx, func(ctx context.Context, pipelines ...pipeline.Pipeline) error {
+			for _, v := range pipelines {
+				if err := v.MatchEvent(e, values); err != nil {
+					log.Infof("Skipping pipeline '%s': %s", v.Name, err)
+					continue
+				}
+
+				log.Infof("Selected pipeline '%s' for event '%s'", v.Name, e)
+				v.Dependencies = []pipeline.Pipeline{}
+				selected = append(selected, v)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if len(selected) == 0 {
+			return fmt.Errorf("no pipelines match event '%s'", e)
 		}
 
 		c := pipeline.NewCollection()
-		if err := c.AddPipelines(pipelines...); err != nil {
+		if err := c.AddPipelines(selected...); err != nil {
 			return err
 		}
 
@@ -208,6 +313,53 @@ func executeWithEvent(
 	}
 }
 
+// executeWithValidation validates the flow of arguments through the collection before any step runs.
+// Arguments are known if the CLI client adds them to the state, or if they are already in the state or its fallback readers, like the '-arg' flags or the environment.
+// Arguments that are not provided are requested from stdin when the step runs, so they are only logged as warnings if stdin prompts are allowed.
+func executeWithValidation(
+	opts clients.CommonOpts,
+	log logrus.FieldLogger,
+	ef executeFunc,
+) executeFunc {
+	return func(ctx context.Context, collection *pipeline.Collection) error {
+		err := clients.ValidateArguments(ctx, collection, opts, func(arg state.Argument) bool {
+			if cli.KnownArgument(arg) {
+				return true
+			}
+
+			if opts.State == nil {
+				return false
+			}
+
+			exists, err := opts.State.Exists(arg)
+			return err == nil && exists
+		})
+
+		var errs pipeline.ArgumentErrors
+		if !errors.As(err, &errs) {
+			if err != nil {
+				return err
+			}
+
+			return ef(ctx, collection)
+		}
+
+		if opts.Args.CanStdinPrompt {
+			for _, v := range errs.Filter(pipeline.ErrorArgumentNotProvided) {
+				log.Warnf("%s; it will be requested from stdin", v.Error())
+			}
+
+			errs = errs.Filter(pipeline.ErrorArgumentProvidedInParallel)
+		}
+
+		if len(errs) != 0 {
+			return errs
+		}
+
+		return ef(ctx, collection)
+	}
+}
+
 func executeWithSignals(
 	ef executeFunc,
 ) executeFunc {
@@ -225,16 +377,27 @@ func executeWithSignals(
 
 // LocalClients define modes that are intended to run a pipeline "locally".
 // These local clients will do things like filter the pipeline based on the selected event with the '-e' flag.
-var LocalModes = []string{"dagger"}
+var LocalModes = []string{"cli", "dagger"}
 
 // Execute runs the provided executeFunc with the appropriate wrappers.
 // All of the arguments are for populating the wrappers.
 func execute(ctx context.Context, collection *pipeline.Collection, name string, opts clients.CommonOpts, n *counter, ef executeFunc) error {
 	logger := opts.Log.WithFields(plog.Combine(plog.TracingFields(ctx), plog.PipelineFields(opts)))
 
+	// The graphs are validated once, now that every pipeline and step has been added.
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+
 	// Wrap with signals watching. If the user submits a SIGTERM/SIGINT/SIGKILL, this function will catch it and return an error.
 	wrapped := executeWithSignals(ef)
 
+	// Before running the pipeline locally, ensure that every step will have the arguments that it requires.
+	// The generator clients validate the arguments themselves, as the values that are known depend on the CI service.
+	if slices.Contains[string](LocalModes, opts.Args.Client) {
+		wrapped = executeWithValidation(opts, logger, wrapped)
+	}
+
 	// If the user supplies a --event or -e argument, check the arguments for the event and reduce the collection
 	// However, we only want to do this type of filtering when we're running locally using the dagger mode.
 	if slices.Contains[string](LocalModes, opts.Args.Client) {
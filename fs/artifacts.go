@@ -2,4 +2,9 @@ package fs
 
 import "github.com/grafana/scribe/pipeline"
 
-func Glob(string) pipeline.Artifact { return nil }
+// Glob creates an artifact that includes every file that matches the pattern, relative to the working directory.
+// The pattern is also used as the name of the artifact, so steps that produce and use the artifact should use the same pattern.
+// To give the artifact a different name, use pipeline.NewArtifact.
+func Glob(pattern string) pipeline.Artifact {
+	return pipeline.NewArtifact(pattern, pattern)
+}
@@ -1,17 +1,38 @@
 package fs
 
-import "github.com/grafana/scribe/pipeline"
+import (
+	"context"
+	"os"
+	"path/filepath"
 
-// FileHasChanged creates a checksum for the file "file" and stores it.
-// If the checksum does not exist in the scribe key store, then it will return false.
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/swfs"
+)
+
+// FileHasChanged creates a checksum for the file or directory "file" which is added to the step's cache key.
+// If the checksum is different than it was in the previous run, then the cache is invalidated and the step will run.
 func FileHasChanged(file string) pipeline.CacheCondition {
-	return func() bool {
-		return false
+	return func(context.Context, pipeline.ActionOpts) ([]byte, error) {
+		path := os.ExpandEnv(file)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			return swfs.HashDirectory(path)
+		}
+
+		// Hash only the requested file by filtering everything else out of its parent directory.
+		return swfs.HashFS(swfs.Filter(os.DirFS(filepath.Dir(path)), filepath.Base(path)))
 	}
 }
 
-// Cache will store the directory or file located at `path` if the conditions return true.
-// If all of the conditions return true, then the step is skipped and the directory is added to the local filesystem.
+// Cache will store the directory or file located at `path` after the step runs.
+// If the values of all of the conditions are unchanged since the last time the step ran, then the step is skipped and the directory is restored to the local filesystem.
 func Cache(path string, conditions ...pipeline.CacheCondition) pipeline.Cacher {
-	return func(pipeline.Step) {}
+	return pipeline.Cacher{
+		Paths:      []string{path},
+		Conditions: conditions,
+	}
 }
@@ -6,6 +6,9 @@ import (
 	"github.com/grafana/scribe/pipeline/clients/cli"
 	"github.com/grafana/scribe/pipeline/clients/dagger"
 	"github.com/grafana/scribe/pipeline/clients/drone"
+	"github.com/grafana/scribe/pipeline/clients/github"
+	"github.com/grafana/scribe/pipeline/clients/gitlab"
+	"github.com/grafana/scribe/pipeline/clients/graph"
 )
 
 var (
@@ -17,6 +20,15 @@ var (
 
 	// ClientDagger
 	ClientDagger = "dagger"
+
+	// ClientGitHub is set when a pipeline is ran using the GitHub client, which is used to generate a GitHub Actions workflow from a Scribe pipeline
+	ClientGitHub = "github"
+
+	// ClientGitLab is set when a pipeline is ran using the GitLab client, which is used to generate a GitLab CI config from a Scribe pipeline
+	ClientGitLab = "gitlab"
+
+	// ClientGraph is set when a pipeline is ran using the graph client, which is used to export the structure of a Scribe pipeline as a DOT, Mermaid, or JSON document
+	ClientGraph = "graph"
 )
 
 func NewDefaultCollection(opts clients.CommonOpts) *pipeline.Collection {
@@ -39,6 +51,9 @@ var ClientInitializers = map[string]InitializerFunc{
 	ClientCLI:    cli.New,
 	ClientDrone:  drone.New,
 	ClientDagger: dagger.New,
+	ClientGitHub: github.New,
+	ClientGitLab: gitlab.New,
+	ClientGraph:  graph.New,
 }
 
 func RegisterClient(name string, initializer InitializerFunc) {
@@ -11,6 +11,17 @@ var (
 	ArgumentBranch    = state.NewStringArgument("git-branch")
 	ArgumentRemoteURL = state.NewStringArgument("remote-url")
 	ArgumentTagName   = state.NewStringArgument("git-tag")
+	// ArgumentChangedFiles is a newline-separated list of the files that were changed by the commit or pull request.
+	ArgumentChangedFiles = state.NewStringArgument("git-changed-files")
+
+	// Pull request arguments
+	ArgumentPullRequestNumber = state.NewInt64Argument("pull-request-number")
+	ArgumentSourceBranch      = state.NewStringArgument("pull-request-source-branch")
+	ArgumentTargetBranch      = state.NewStringArgument("pull-request-target-branch")
+
+	// Cron and promotion arguments
+	ArgumentCronName      = state.NewStringArgument("cron-name")
+	ArgumentPromoteTarget = state.NewStringArgument("promote-target")
 
 	// Standard pipeline arguments
 	ArgumentWorkingDir = state.NewStringArgument("workdir")
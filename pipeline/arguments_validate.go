@@ -0,0 +1,218 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/scribe/state"
+)
+
+var (
+	ErrorArgumentNotProvided        = errors.New("argument is not provided")
+	ErrorArgumentProvidedInParallel = errors.New("argument is provided by steps that run in parallel")
+)
+
+// ArgumentKnownFunc returns true if the value of the argument is available before any step runs, like a value that the client knows how to retrieve or a value provided with the '-arg' flag.
+type ArgumentKnownFunc func(state.Argument) bool
+
+// ArgumentError is a problem with the flow of an argument through a pipeline.
+// Err is either ErrorArgumentNotProvided or ErrorArgumentProvidedInParallel.
+type ArgumentError struct {
+	Err      error
+	Pipeline string
+	// Steps is the step that requires the argument, or every step that provides it in parallel.
+	Steps    []string
+	Argument state.Argument
+}
+
+func (e ArgumentError) Error() string {
+	if errors.Is(e.Err, ErrorArgumentProvidedInParallel) {
+		return fmt.Sprintf("pipeline '%s': argument '%s' is provided by steps that run in parallel (%s); only one of those values will be in the state", e.Pipeline, e.Argument.Key, strings.Join(e.Steps, ", "))
+	}
+
+	return fmt.Sprintf("pipeline '%s': step '%s' requires argument '%s', but it is not provided by a previous step, the client, an event, or the '-arg=%s={value}' flag", e.Pipeline, strings.Join(e.Steps, ", "), e.Argument.Key, e.Argument.Key)
+}
+
+func (e ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// ArgumentErrors is every problem found by ValidateArguments.
+type ArgumentErrors []ArgumentError
+
+func (e ArgumentErrors) Error() string {
+	s := make([]string, len(e))
+	for i, v := range e {
+		s[i] = v.Error()
+	}
+
+	return strings.Join(s, "\n")
+}
+
+// Filter returns the errors that match the target error using errors.Is.
+func (e ArgumentErrors) Filter(target error) ArgumentErrors {
+	errs := ArgumentErrors{}
+	for _, v := range e {
+		if errors.Is(v, target) {
+			errs = append(errs, v)
+		}
+	}
+
+	return errs
+}
+
+// pipelineProvides adds every argument that is provided by a step in the pipeline, or in one of the pipelines that it depends on, to 'provided'.
+func pipelineProvides(p Pipeline, provided map[string]bool, visited map[int64]bool) {
+	if visited[p.ID] {
+		return
+	}
+	visited[p.ID] = true
+
+	for _, node := range p.Graph.Nodes {
+		for _, step := range node.Value.Steps {
+			for _, arg := range step.ProvidesArgs {
+				provided[arg.Key] = true
+			}
+		}
+	}
+
+	for _, v := range p.Dependencies {
+		pipelineProvides(v, provided, visited)
+	}
+}
+
+// validatePipelineArguments validates the flow of arguments through the steps of a single pipeline.
+// 'available' are the arguments that are available before any step in the pipeline runs.
+func validatePipelineArguments(p Pipeline, available map[string]bool) (ArgumentErrors, error) {
+	var (
+		errs = ArgumentErrors{}
+		// providers is every step that provides an argument, by argument key. Each step is stored in a StepList with the ID of the list that it is in.
+		providers    = map[string][]StepList{}
+		provideOrder = []state.Argument{}
+		order        = []StepList{}
+	)
+
+	nodes, err := p.Graph.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range nodes {
+		if n.ID == 0 {
+			continue
+		}
+
+		order = append(order, n.Value)
+		for _, step := range n.Value.Steps {
+			for _, arg := range step.ProvidesArgs {
+				if _, ok := providers[arg.Key]; !ok {
+					provideOrder = append(provideOrder, arg)
+				}
+				providers[arg.Key] = append(providers[arg.Key], NewStepList(n.ID, step))
+			}
+		}
+	}
+
+	for _, list := range order {
+		for _, step := range list.Steps {
+			provides := map[string]bool{}
+			for _, arg := range step.ProvidesArgs {
+				provides[arg.Key] = true
+			}
+
+			for _, arg := range step.Arguments {
+				if available[arg.Key] || provides[arg.Key] {
+					continue
+				}
+
+				provided := false
+				for _, v := range providers[arg.Key] {
+					if v.ID != list.ID && p.Graph.Path(v.ID, list.ID) != nil {
+						provided = true
+						break
+					}
+				}
+
+				if !provided {
+					errs = append(errs, ArgumentError{
+						Err:      ErrorArgumentNotProvided,
+						Pipeline: p.Name,
+						Steps:    []string{step.Name},
+						Argument: arg,
+					})
+				}
+			}
+		}
+	}
+
+	// Arguments that are provided by more than one step are only a problem if those steps can run at the same time;
+	// that is, if they are in the same list of steps, or if neither list of steps runs after the other.
+	for _, arg := range provideOrder {
+		lists := providers[arg.Key]
+		for i := range lists {
+			for j := i + 1; j < len(lists); j++ {
+				a, b := lists[i], lists[j]
+				if a.ID != b.ID && (p.Graph.Path(a.ID, b.ID) != nil || p.Graph.Path(b.ID, a.ID) != nil) {
+					continue
+				}
+
+				errs = append(errs, ArgumentError{
+					Err:      ErrorArgumentProvidedInParallel,
+					Pipeline: p.Name,
+					Steps:    []string{a.Steps[0].Name, b.Steps[0].Name},
+					Argument: arg,
+				})
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+// ValidateArguments walks through every pipeline in the order that they run and ensures that every argument that a step requires is available when the step runs.
+// An argument is available if it is provided by a step that runs before it, by a step in a pipeline that this pipeline depends on, by one of the pipeline's events, or if the 'known' function returns true.
+// Arguments that are provided by more than one step that can run at the same time are also reported, as it is not clear which value will be in the state.
+// Every problem is returned in the list of ArgumentErrors; the error is only returned if the pipelines could not be walked.
+func ValidateArguments(ctx context.Context, w Walker, known ArgumentKnownFunc) (ArgumentErrors, error) {
+	errs := ArgumentErrors{}
+
+	if err := w.WalkPipelines(ctx, func(ctx context.Context, pipelines ...Pipeline) error {
+		for _, p := range pipelines {
+			available := map[string]bool{}
+			for _, e := range p.Events {
+				for _, arg := range e.Provides {
+					available[arg.Key] = true
+				}
+			}
+
+			visited := map[int64]bool{p.ID: true}
+			for _, v := range p.Dependencies {
+				pipelineProvides(v, available, visited)
+			}
+
+			for _, node := range p.Graph.Nodes {
+				for _, step := range node.Value.Steps {
+					for _, arg := range step.Arguments {
+						if !available[arg.Key] && known != nil && known(arg) {
+							available[arg.Key] = true
+						}
+					}
+				}
+			}
+
+			e, err := validatePipelineArguments(p, available)
+			if err != nil {
+				return err
+			}
+
+			errs = append(errs, e...)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
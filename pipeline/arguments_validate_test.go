@@ -0,0 +1,91 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/testutil"
+)
+
+func validateSteps(t *testing.T, known pipeline.ArgumentKnownFunc, lists ...pipeline.StepList) pipeline.ArgumentErrors {
+	t.Helper()
+	col, err := pipeline.NewCollectionWithSteps("test", lists...)
+	testutil.EnsureError(t, err, nil)
+
+	errs, err := pipeline.ValidateArguments(context.Background(), col, known)
+	testutil.EnsureError(t, err, nil)
+
+	return errs
+}
+
+func ensureArgumentErrors(t *testing.T, errs pipeline.ArgumentErrors, expected ...error) {
+	t.Helper()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected '%d' errors but got '%d': %v", len(expected), len(errs), errs)
+	}
+
+	for i, v := range expected {
+		if !errors.Is(errs[i], v) {
+			t.Fatalf("Expected error '%v' but got '%v'", v, errs[i])
+		}
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	var (
+		arg      = state.NewStringArgument("version")
+		producer = pipeline.NamedStep("producer", nil).Provides(arg)
+		consumer = pipeline.NamedStep("consumer", nil).Requires(arg)
+	)
+
+	t.Run("Arguments provided by a previous step should be valid", func(t *testing.T) {
+		first := pipeline.NewStepList(1, producer)
+		second := pipeline.NewStepList(2, consumer)
+		second.Dependencies = []pipeline.StepList{first}
+
+		ensureArgumentErrors(t, validateSteps(t, nil, first, second))
+	})
+
+	t.Run("Arguments that are not provided should be reported", func(t *testing.T) {
+		errs := validateSteps(t, nil, pipeline.NewStepList(1, consumer))
+		ensureArgumentErrors(t, errs, pipeline.ErrorArgumentNotProvided)
+		if errs[0].Steps[0] != "consumer" || errs[0].Argument != arg {
+			t.Fatalf("Unexpected error: %v", errs[0])
+		}
+	})
+
+	t.Run("Arguments that are known should be valid", func(t *testing.T) {
+		known := func(a state.Argument) bool {
+			return a == arg
+		}
+
+		ensureArgumentErrors(t, validateSteps(t, known, pipeline.NewStepList(1, consumer)))
+	})
+
+	t.Run("Arguments provided by an event should be valid", func(t *testing.T) {
+		step := pipeline.NamedStep("clone", nil).Requires(pipeline.ArgumentCommitSHA)
+		ensureArgumentErrors(t, validateSteps(t, nil, pipeline.NewStepList(1, step)))
+	})
+
+	t.Run("Arguments provided by a step that runs in parallel should be reported", func(t *testing.T) {
+		errs := validateSteps(t, nil, pipeline.NewStepList(1, producer, consumer))
+		ensureArgumentErrors(t, errs, pipeline.ErrorArgumentNotProvided)
+	})
+
+	t.Run("Arguments provided by more than one parallel step should be reported", func(t *testing.T) {
+		other := producer.WithName("other producer")
+		errs := validateSteps(t, nil, pipeline.NewStepList(1, producer, other))
+		ensureArgumentErrors(t, errs, pipeline.ErrorArgumentProvidedInParallel)
+	})
+
+	t.Run("Arguments provided by more than one sequential step should be valid", func(t *testing.T) {
+		first := pipeline.NewStepList(1, producer)
+		second := pipeline.NewStepList(2, producer.WithName("other producer"))
+		second.Dependencies = []pipeline.StepList{first}
+
+		ensureArgumentErrors(t, validateSteps(t, nil, first, second))
+	})
+}
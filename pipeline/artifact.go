@@ -1,3 +1,108 @@
 package pipeline
 
-type Artifact interface{}
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/swfs"
+	"github.com/grafana/scribe/tarfs"
+)
+
+// An Artifact is a group of files that is produced by one step (using Step.WithOutput) and used by other steps (using Step.WithInput).
+// After the producing step runs, the files that match the artifact's patterns are packaged and stored in the state.
+// Before a consuming step runs, the files are extracted into its working directory.
+type Artifact struct {
+	// Name uniquely identifies the artifact. Steps that use the artifact as an input are matched with the step that produces it by name.
+	Name string
+
+	// Patterns are the glob patterns, relative to the working directory, of the files that make up the artifact.
+	// The pattern syntax is the same as `path.Match`. If a pattern matches a directory, then the entire directory is included.
+	Patterns []string
+}
+
+// NewArtifact creates a new Artifact with the given name that includes every file that matches one of the patterns.
+func NewArtifact(name string, patterns ...string) Artifact {
+	return Artifact{
+		Name:     name,
+		Patterns: patterns,
+	}
+}
+
+// Argument returns the state argument that the packaged artifact is stored with.
+func (a Artifact) Argument() state.Argument {
+	return state.NewFileArgument(fmt.Sprintf("artifact-%s", a.Name))
+}
+
+// ArtifactNames returns the name of each Artifact in the list.
+func ArtifactNames(artifacts []Artifact) []string {
+	v := make([]string, len(artifacts))
+	for i := range artifacts {
+		v[i] = artifacts[i].Name
+	}
+
+	return v
+}
+
+func artifactPaths(dir fs.FS, a Artifact) ([]string, error) {
+	paths := []string{}
+	for _, pattern := range a.Patterns {
+		matches, err := fs.Glob(dir, filepath.ToSlash(filepath.Clean(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s' in artifact '%s': %w", pattern, a.Name, err)
+		}
+
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
+
+// PackageArtifact packages every file in 'dir' that matches the artifact's patterns into a gzipped tar archive and stores it in the state.
+// If no files match the artifact's patterns, then an error is returned.
+func PackageArtifact(s *state.State, a Artifact, dir string) error {
+	root := os.DirFS(dir)
+	paths, err := artifactPaths(root, a)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no files found for artifact '%s' matching '%v'", a.Name, a.Patterns)
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(tarfs.Write(w, swfs.Filter(root, paths...)))
+	}()
+
+	if err := s.SetFileReader(a.Argument(), r); err != nil {
+		r.CloseWithError(err)
+		return fmt.Errorf("error storing artifact '%s' in state: %w", a.Name, err)
+	}
+
+	return nil
+}
+
+// ExtractArtifact retrieves the packaged artifact from the state and extracts it into 'dir'.
+func ExtractArtifact(s *state.State, a Artifact, dir string) error {
+	f, err := s.GetFile(a.Argument())
+	if err != nil {
+		return fmt.Errorf("error retrieving artifact '%s' from state: %w", a.Name, err)
+	}
+	defer f.Close()
+
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	if err := tarfs.Untar(path, f); err != nil {
+		return fmt.Errorf("error extracting artifact '%s': %w", a.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package pipeline_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestArtifacts(t *testing.T) {
+	var (
+		src = t.TempDir()
+		dst = t.TempDir()
+	)
+
+	handler, err := state.NewFilesystemState(filepath.Join(t.TempDir(), "state.json"))
+	testutil.EnsureError(t, err, nil)
+
+	s := &state.State{
+		Handler: handler,
+		Log:     logrus.New(),
+	}
+
+	testutil.EnsureError(t, os.MkdirAll(filepath.Join(src, "bin"), 0755), nil)
+	testutil.EnsureError(t, os.WriteFile(filepath.Join(src, "bin", "app"), []byte("app"), 0644), nil)
+	testutil.EnsureError(t, os.WriteFile(filepath.Join(src, "README.md"), []byte("readme"), 0644), nil)
+
+	t.Run("An artifact should be extracted with only the files that match its patterns", func(t *testing.T) {
+		artifact := pipeline.NewArtifact("bin", "bin/*")
+		testutil.EnsureError(t, pipeline.PackageArtifact(s, artifact, src), nil)
+		testutil.EnsureError(t, pipeline.ExtractArtifact(s, artifact, dst), nil)
+
+		b, err := os.ReadFile(filepath.Join(dst, "bin", "app"))
+		testutil.EnsureError(t, err, nil)
+		if string(b) != "app" {
+			t.Fatalf("Expected extracted file to contain 'app' but it contains '%s'", string(b))
+		}
+
+		if _, err := os.Stat(filepath.Join(dst, "README.md")); !os.IsNotExist(err) {
+			t.Fatalf("Expected 'README.md' to not be extracted, but got '%v'", err)
+		}
+	})
+
+	t.Run("PackageArtifact should return an error if no files match", func(t *testing.T) {
+		artifact := pipeline.NewArtifact("none", "dist/*")
+		if err := pipeline.PackageArtifact(s, artifact, src); err == nil {
+			t.Fatal("Expected an error but received none")
+		}
+	})
+}
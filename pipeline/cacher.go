@@ -1,12 +1,289 @@
 package pipeline
 
-// a CacheCondition should return true if the cacher should cache the step.
-// In other words, returning false means that the step must run.
-type CacheCondition func() bool
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/grafana/scribe/cache"
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/swfs"
+	"github.com/grafana/scribe/tarfs"
+)
+
+// A CacheCondition returns a value that is added to the cache key of a step.
+// If the value changes in between runs, then the cache is invalidated and the step must run.
+// The most common example is the hash of a file, like `package-lock.json`, which is provided by `fs.FileHasChanged`.
+type CacheCondition func(context.Context, ActionOpts) ([]byte, error)
 
 // A Cacher defines behavior for caching data.
 // Some behaviors that can happen when dealing with a cacheable step:
 // * The provided Step, using an expensive process, produces some consistent output, possibly on the filesystem. If nothing changes in between runs, then we can re-use the output in the current step and skip this one.
-//   * The most common example of this is `npm install` producing the `node_modules` folder, which can be re-used if `package-lock.json` is unchanged.
+//   - The most common example of this is `npm install` producing the `node_modules` folder, which can be re-used if `package-lock.json` is unchanged.
+//
 // * The provided Step, using an expensive process, calculates a value. If nothing changes in between runs, then this value can be reused.
-type Cacher func(Step)
+type Cacher struct {
+	// Paths are the files or directories that the step produces.
+	// They are stored in the cache after the step runs successfully and are restored when the step is skipped.
+	// Environment variables in paths, like `$GOPATH/pkg`, are expanded when the step runs.
+	Paths []string
+
+	// Conditions are the declared inputs of the step. Every value they return is added to the cache key.
+	Conditions []CacheCondition
+}
+
+// cacheOutput describes a single cached path in the cache manifest.
+type cacheOutput struct {
+	Path string `json:"path"`
+	Dir  bool   `json:"dir"`
+}
+
+// cacheManifest is stored under the cache key after all of the outputs have been stored.
+// Because it is written last, a partially populated cache entry is never treated as a hit.
+type cacheManifest struct {
+	Outputs []cacheOutput `json:"outputs"`
+}
+
+func outputKey(key string, i int) string {
+	return fmt.Sprintf("%s-%d", key, i)
+}
+
+// cacheArgumentValue returns the value of the argument in the state if it is a scalar value that is already available.
+// Secrets and filesystem arguments are intentionally excluded; only their key and type are added to the cache key.
+func cacheArgumentValue(s *state.State, arg state.Argument) (any, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	if exists, err := s.Exists(arg); err != nil || !exists {
+		return nil, false
+	}
+
+	var (
+		v   any
+		err error
+	)
+
+	switch arg.Type {
+	case state.ArgumentTypeString:
+		v, err = s.GetString(arg)
+	case state.ArgumentTypeInt64:
+		v, err = s.GetInt64(arg)
+	case state.ArgumentTypeFloat64:
+		v, err = s.GetFloat64(arg)
+	case state.ArgumentTypeBool:
+		v, err = s.GetBool(arg)
+	case state.ArgumentTypeStringSlice:
+		v, err = s.GetStringSlice(arg)
+	case state.ArgumentTypeStringMap:
+		v, err = s.GetStringMap(arg)
+	case state.ArgumentTypeJSON:
+		v, err = state.GetJSON[json.RawMessage](s, arg)
+	default:
+		return nil, false
+	}
+
+	if err != nil {
+		return nil, false
+	}
+
+	return v, true
+}
+
+// CacheKey returns the content key for a cached step.
+// The key is built from the step's image, its required arguments (and their values, when they are scalar values found in the state), and the values returned by every CacheCondition.
+func CacheKey(ctx context.Context, c Cacher, opts ActionOpts) (string, error) {
+	hash := sha256.New()
+	enc := json.NewEncoder(hash)
+
+	if err := enc.Encode(opts.Step.Image); err != nil {
+		return "", err
+	}
+
+	args := make([]state.Argument, len(opts.Step.Arguments))
+	copy(args, opts.Step.Arguments)
+	sort.Slice(args, func(i, j int) bool {
+		return args[i].Key < args[j].Key
+	})
+
+	for _, arg := range args {
+		value, _ := cacheArgumentValue(opts.State, arg)
+		if err := enc.Encode([]any{arg.Key, arg.Type, value}); err != nil {
+			return "", err
+		}
+	}
+
+	for _, path := range c.Paths {
+		if err := enc.Encode(path); err != nil {
+			return "", err
+		}
+	}
+
+	for i, condition := range c.Conditions {
+		b, err := condition(ctx, opts)
+		if err != nil {
+			return "", fmt.Errorf("error evaluating cache condition '%d': %w", i, err)
+		}
+
+		// The output is encoded like the other values so that outputs like ("ab", "c") and ("a", "bc") don't produce the same key.
+		if err := enc.Encode(b); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func restoreOutput(store cache.Store, key string, output cacheOutput) error {
+	r, err := store.Get(key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if output.Dir {
+		if err := os.RemoveAll(output.Path); err != nil {
+			return err
+		}
+
+		return tarfs.Untar(output.Path, r)
+	}
+
+	return swfs.CopyFileReader(r, output.Path)
+}
+
+func storeOutput(store cache.Store, key string, path string) (cacheOutput, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return cacheOutput{}, fmt.Errorf("cached path '%s' was not produced by the step: %w", path, err)
+	}
+
+	output := cacheOutput{
+		Path: path,
+		Dir:  info.IsDir(),
+	}
+
+	if !info.IsDir() {
+		f, err := os.Open(path)
+		if err != nil {
+			return cacheOutput{}, err
+		}
+		defer f.Close()
+
+		return output, store.Put(key, f)
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(tarfs.Write(w, os.DirFS(path)))
+	}()
+
+	if err := store.Put(key, r); err != nil {
+		r.CloseWithError(err)
+		return cacheOutput{}, err
+	}
+
+	return output, nil
+}
+
+func restoreCache(store cache.Store, key string) (bool, error) {
+	r, err := store.Get(key)
+	if err != nil {
+		if errors.Is(err, cache.ErrorNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+	defer r.Close()
+
+	manifest := cacheManifest{}
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return false, fmt.Errorf("error decoding cache manifest '%s': %w", key, err)
+	}
+
+	for i, v := range manifest.Outputs {
+		if err := restoreOutput(store, outputKey(key, i), v); err != nil {
+			return false, fmt.Errorf("error restoring cached path '%s': %w", v.Path, err)
+		}
+	}
+
+	return true, nil
+}
+
+func populateCache(store cache.Store, key string, paths []string) error {
+	manifest := cacheManifest{
+		Outputs: make([]cacheOutput, len(paths)),
+	}
+
+	for i, path := range paths {
+		output, err := storeOutput(store, outputKey(key, i), path)
+		if err != nil {
+			return err
+		}
+
+		manifest.Outputs[i] = output
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(buf).Encode(manifest); err != nil {
+		return err
+	}
+
+	return store.Put(key, buf)
+}
+
+// CacheAction wraps the action so that it is skipped if the cache key (see CacheKey) matches a previous run.
+// When the action is skipped, the paths defined in the Cacher are restored from the cache store instead.
+// When the action runs successfully, the paths are stored in the cache store.
+// If there is no cache store in the ActionOpts, then the action is always ran.
+func CacheAction(action Action, c Cacher) Action {
+	return func(ctx context.Context, opts ActionOpts) error {
+		log := opts.Logger
+		store := opts.Cache
+		if store == nil {
+			log.Debugln("No cache store configured; running step without cache")
+			return action(ctx, opts)
+		}
+
+		paths := make([]string, len(c.Paths))
+		for i, v := range c.Paths {
+			paths[i] = filepath.Clean(os.ExpandEnv(v))
+		}
+
+		key, err := CacheKey(ctx, Cacher{Paths: paths, Conditions: c.Conditions}, opts)
+		if err != nil {
+			return fmt.Errorf("error generating cache key: %w", err)
+		}
+
+		ok, err := restoreCache(store, key)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			log.WithField("cache_key", key).Infoln("cache hit; restored cached paths and skipped step")
+			return nil
+		}
+
+		log.WithField("cache_key", key).Debugln("cache miss; running step")
+
+		if err := action(ctx, opts); err != nil {
+			return err
+		}
+
+		if err := populateCache(store, key, paths); err != nil {
+			return fmt.Errorf("error storing step outputs in cache: %w", err)
+		}
+
+		return nil
+	}
+}
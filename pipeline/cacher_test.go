@@ -0,0 +1,115 @@
+package pipeline_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/scribe/cache"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func staticCondition(v string) pipeline.CacheCondition {
+	return func(context.Context, pipeline.ActionOpts) ([]byte, error) {
+		return []byte(v), nil
+	}
+}
+
+func TestCacheAction(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		dir    = t.TempDir()
+		output = filepath.Join(t.TempDir(), "output")
+		runs   = 0
+	)
+
+	store, err := cache.NewLocalStore(dir)
+	testutil.EnsureError(t, err, nil)
+
+	action := func(context.Context, pipeline.ActionOpts) error {
+		runs++
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(output, "file.txt"), []byte("cached"), 0644)
+	}
+
+	opts := pipeline.ActionOpts{
+		Cache:  store,
+		Logger: logrus.New(),
+		Step:   pipeline.NamedStep("cached step", action).WithImage("alpine:latest"),
+	}
+
+	cached := pipeline.CacheAction(action, pipeline.Cacher{
+		Paths:      []string{output},
+		Conditions: []pipeline.CacheCondition{staticCondition("a")},
+	})
+
+	t.Run("The action should run if it has not been cached", func(t *testing.T) {
+		testutil.EnsureError(t, cached(ctx, opts), nil)
+		if runs != 1 {
+			t.Fatalf("Expected action to run once, but it ran '%d' times", runs)
+		}
+	})
+
+	t.Run("The action should be skipped and the outputs restored if the cache key is unchanged", func(t *testing.T) {
+		testutil.EnsureError(t, os.RemoveAll(output), nil)
+		testutil.EnsureError(t, cached(ctx, opts), nil)
+		if runs != 1 {
+			t.Fatalf("Expected action to be skipped, but it ran '%d' times", runs)
+		}
+
+		b, err := os.ReadFile(filepath.Join(output, "file.txt"))
+		testutil.EnsureError(t, err, nil)
+		if string(b) != "cached" {
+			t.Fatalf("Unexpected content in restored file: '%s'", string(b))
+		}
+	})
+
+	t.Run("The action should run if a condition has changed", func(t *testing.T) {
+		changed := pipeline.CacheAction(action, pipeline.Cacher{
+			Paths:      []string{output},
+			Conditions: []pipeline.CacheCondition{staticCondition("b")},
+		})
+
+		testutil.EnsureError(t, changed(ctx, opts), nil)
+		if runs != 2 {
+			t.Fatalf("Expected action to run twice, but it ran '%d' times", runs)
+		}
+	})
+
+	t.Run("The action should run if the image has changed", func(t *testing.T) {
+		o := opts
+		o.Step = o.Step.WithImage("alpine:3")
+		testutil.EnsureError(t, cached(ctx, o), nil)
+		if runs != 3 {
+			t.Fatalf("Expected action to run three times, but it ran '%d' times", runs)
+		}
+	})
+}
+
+func TestCacheKey(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		opts = pipeline.ActionOpts{
+			Step: pipeline.NamedStep("cached step", pipeline.DefaultAction).WithImage("alpine:latest"),
+		}
+	)
+
+	a, err := pipeline.CacheKey(ctx, pipeline.Cacher{
+		Conditions: []pipeline.CacheCondition{staticCondition("ab"), staticCondition("c")},
+	}, opts)
+	testutil.EnsureError(t, err, nil)
+
+	b, err := pipeline.CacheKey(ctx, pipeline.Cacher{
+		Conditions: []pipeline.CacheCondition{staticCondition("a"), staticCondition("bc")},
+	}, opts)
+	testutil.EnsureError(t, err, nil)
+
+	if a == b {
+		t.Fatalf("Expected different conditions to produce different keys, but both produced '%s'", a)
+	}
+}
@@ -0,0 +1,51 @@
+package clients
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/state"
+	"github.com/sirupsen/logrus"
+)
+
+// ValidateArguments validates the flow of arguments through the pipelines before a client runs them or generates a config for them.
+// An argument is known if it was provided with the '-arg' flag or if the 'known' function returns true.
+// If there are any problems with the flow of arguments, then the returned error is a pipeline.ArgumentErrors that lists every one.
+func ValidateArguments(ctx context.Context, w pipeline.Walker, opts CommonOpts, known pipeline.ArgumentKnownFunc) error {
+	errs, err := pipeline.ValidateArguments(ctx, w, func(arg state.Argument) bool {
+		if opts.Args != nil {
+			if _, err := opts.Args.ArgMap.Get(arg.Key); err == nil {
+				return true
+			}
+		}
+
+		return known(arg)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// WarnArguments validates the flow of arguments like ValidateArguments, but logs every problem as a warning instead of returning it.
+// It is used by the clients that generate configs, as the values of arguments can be provided to those pipelines in ways that are not known when the config is generated, like environment variables.
+func WarnArguments(ctx context.Context, w pipeline.Walker, opts CommonOpts, log logrus.FieldLogger, known pipeline.ArgumentKnownFunc) error {
+	err := ValidateArguments(ctx, w, opts, known)
+
+	var errs pipeline.ArgumentErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+
+	for _, v := range errs {
+		log.Warnln(v.Error())
+	}
+
+	return nil
+}
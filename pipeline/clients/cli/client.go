@@ -24,21 +24,14 @@ func (c *Client) PipelineWalkFunc(w pipeline.Walker, wf pipeline.StepWalkFunc) f
 		log.Debugln("Running pipeline(s)", pipeline.PipelineNames(pipelines))
 
 		var (
-			wg = syncutil.NewPipelineWaitGroup()
+			wg = syncutil.NewPipelineWaitGroupWithPolicy(c.Opts.FailurePolicy())
 		)
 
 		// These pipelines run in parallel, but must all complete before continuing on to the next set.
 		for i, v := range pipelines {
-			// If this is a sub-pipeline, then run these steps without waiting on other pipeliens to complete.
-			// However, if a sub-pipeline returns an error, then we shoud(?) stop.
-			// TODO: This is probably not true... if a sub-pipeline stops then users will probably want to take note of it, but let the rest of the pipeline continue.
-			// and see a report of the failure towards the end of the execution.
-			if v.Type == pipeline.PipelineTypeSub {
-				go func(ctx context.Context, p pipeline.Pipeline) {
-					if err := c.runPipeline(ctx, w, wf, p); err != nil {
-						c.Log.WithError(err).Errorln("sub-pipeline failed")
-					}
-				}(ctx, pipelines[i])
+			// Sub-pipelines run in the background without blocking the next set of pipelines. They are joined, and their errors are reported, once every other pipeline is done (see Done).
+			if v.Type == pipeline.PipelineTypeSub && c.subs != nil {
+				c.subs.Go(pipelines[i], w, wf)
 				continue
 			}
 
@@ -68,6 +61,12 @@ func (c *Client) StepWalkFunc(ctx context.Context, steps ...pipeline.Step) error
 type Client struct {
 	Opts clients.CommonOpts
 	Log  *logrus.Logger
+
+	// scheduler is shared by every step so that the '-parallelism' argument and the steps' resources apply to the whole run.
+	scheduler *syncutil.Scheduler
+
+	// subs tracks the sub-pipelines that are running in the background. It is created in Done; if it is nil, then sub-pipelines run like any other pipeline.
+	subs *syncutil.SubPipelineGroup
 }
 
 func (c *Client) Validate(step pipeline.Step) error {
@@ -97,17 +96,23 @@ func (c *Client) Done(ctx context.Context, w pipeline.Walker) error {
 		Tracer: c.Opts.Tracer,
 	}
 
+	artifactWrapper := &wrappers.ArtifactWrapper{
+		Opts: c.Opts,
+		Log:  c.Log,
+	}
+
 	// Because these wrappers wrap the actions of each step, the first wrapper typically runs first.
 	stepWalkFunc := traceWrapper.Wrap(c.StepWalkFunc)
 	stepWalkFunc = logWrapper.Wrap(stepWalkFunc)
+	stepWalkFunc = artifactWrapper.Wrap(stepWalkFunc)
 
 	pipelineWalkFunc := c.PipelineWalkFunc(w, stepWalkFunc)
 
-	return w.WalkPipelines(ctx, pipelineWalkFunc)
-}
+	c.subs, ctx = syncutil.NewSubPipelineGroup(ctx, c.Opts.FailurePolicy(), c.Log)
+	err := w.WalkPipelines(ctx, pipelineWalkFunc)
 
-func (c *Client) runPipeline(ctx context.Context, w pipeline.Walker, wf pipeline.StepWalkFunc, p pipeline.Pipeline) error {
-	return w.WalkSteps(ctx, p.ID, wf)
+	// Done does not return until every sub-pipeline is done, so that their failures are included in the result.
+	return c.subs.Join(err)
 }
 
 func (c *Client) prepopulateState(s *state.State) error {
@@ -135,7 +140,7 @@ func (c *Client) runSteps(ctx context.Context, steps []pipeline.Step) error {
 	c.Log.Debugln("Running steps in parallel:", len(steps))
 
 	var (
-		wg = syncutil.NewStepWaitGroup()
+		wg = syncutil.NewStepWaitGroupWithPolicy(c.Opts.FailurePolicy()).WithScheduler(c.scheduler)
 	)
 	s := c.Opts.State
 
@@ -151,10 +156,12 @@ func (c *Client) runSteps(ctx context.Context, steps []pipeline.Step) error {
 			Tracer:  c.Opts.Tracer,
 			Version: c.Opts.Version,
 			Logger:  log,
+			Step:    v,
+			Cache:   c.Opts.Cache,
 		})
 	}
 
-	// If we wanted to allow users to configure a timeout, here would be the place. To configure a time out for the list of steps, use context.WithTimeout.
+	// Each step's timeout and retries (see Step.WithTimeout and Step.WithRetry) are enforced by the StepWaitGroup.
 	if err := wg.Wait(ctx); err != nil {
 		return fmt.Errorf("error waiting for steps (%s) to complete: %w", pipeline.StepNames(steps), err)
 	}
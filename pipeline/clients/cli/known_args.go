@@ -78,3 +78,9 @@ var KnownValues = map[state.Argument]func(*state.State) error{
 	pipeline.ArgumentSourceFS:   setSourceFS,
 	pipeline.ArgumentBuildID:    setBuildID,
 }
+
+// KnownArgument returns true if the argument is in KnownValues, so its value is added to the state before any step runs.
+func KnownArgument(arg state.Argument) bool {
+	_, ok := KnownValues[arg]
+	return ok
+}
@@ -2,70 +2,161 @@ package dagger
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"dagger.io/dagger"
 	"github.com/grafana/scribe/args"
 	"github.com/grafana/scribe/cmdutil"
 	"github.com/grafana/scribe/pipeline"
 	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/grafana/scribe/state"
 	"github.com/grafana/scribe/syncutil"
 	"github.com/sirupsen/logrus"
 )
 
+// ContainerTimeoutGrace is added to the longest time that a step can take to get the timeout of the container that runs it.
+// It allows time for the container to start and for the Scribe CLI in the container to report the step's timeout.
+var ContainerTimeoutGrace = time.Minute
+
 type Client struct {
 	Opts clients.CommonOpts
 
 	Log *logrus.Logger
+
+	// scheduler is shared by every container so that the '-parallelism' argument and the steps' resources apply to the whole run.
+	scheduler *syncutil.Scheduler
+
+	// subs tracks the sub-pipelines that are running in the background. It is created in Done; if it is nil, then sub-pipelines run like any other pipeline.
+	subs *syncutil.SubPipelineGroup
 }
 
 // WalkSteps is the handler for walking steps provided to the pipeline.Walker.
 // It is called once per parallel group of steps.
 // Every step pper pipeline with Dagger is executed using the same connection.
-func (c *Client) StepWalkFunc(d *dagger.Client, bin *dagger.Directory, src *dagger.Directory, state *dagger.CacheVolume, path string) pipeline.StepWalkFunc {
+func (c *Client) StepWalkFunc(d *dagger.Client, bin *dagger.Directory, src *dagger.Directory, state *dagger.CacheVolume, cache *dagger.CacheVolume, path string) pipeline.StepWalkFunc {
 	return func(ctx context.Context, steps ...pipeline.Step) error {
+		wg := syncutil.NewStepWaitGroupWithPolicy(c.Opts.FailurePolicy()).WithScheduler(c.scheduler)
 		for _, step := range steps {
 			log := c.Log.WithFields(logrus.Fields{
 				"step": step.Name,
 			})
 
-			log.Infoln("Running steps using dagger client...")
-			binPath := "/opt/scribe/pipeline"
-			runner := d.Container().From(step.Image).
-				WithMountedDirectory("/opt/scribe", bin).
-				WithMountedDirectory("/var/scribe", src).
-				WithMountedCache("/var/scribe-state", state, dagger.ContainerWithMountedCacheOpts{}).
-				WithEntrypoint([]string{}).
-				WithWorkdir("/var/scribe")
-
-			cmd, err := cmdutil.StepCommand(cmdutil.CommandOpts{
-				CompiledPipeline: binPath,
-				Step:             step,
-				PipelineArgs: args.PipelineArgs{
-					Path:  path,
-					State: "file:///var/scribe-state/state.json",
-				},
-			})
-			if err != nil {
-				return err
+			// The step's status and event conditions are checked here, as the container only runs a single step and does not know if a step before it failed.
+			// Conditions added with Step.When are checked by the Scribe CLI in the container, as they read the state in the container.
+			if err := step.CheckStatus(pipeline.StatusFromContext(ctx)); err != nil {
+				log.WithField("status", "skipped").Infoln(err.Error())
+				continue
 			}
 
-			// Some containers have entrypoints that can make `Exec` inconsistent. This attempts to disable / override that behavior.
-			//runner = runner.WithEntrypoint([]string{})
-			log.WithField("command", strings.Join(cmd, " ")).Debugln("Registering container with command...")
-			runner = runner.WithExec(cmd)
-
-			if stdout, err := runner.Stderr(ctx); err == nil {
-				log.WithField("stream", "stdout").Infoln(stdout)
+			if err := step.CheckEvent(c.Opts.Args.Event, c.Opts.State); err != nil {
+				log.WithField("status", "skipped").Infoln(err.Error())
+				continue
 			}
 
-			if stderr, err := runner.Stderr(ctx); err == nil {
-				log.WithField("stream", "stderr").Infoln(stderr)
+			// The step's timeout and retries are enforced by the Scribe CLI that runs inside of the container.
+			// The container is only stopped if it runs for longer than every attempt could take, in case the CLI in the container can not stop it.
+			container := step
+			container.Retry = pipeline.Retry{}
+			if d := step.MaxDuration(); d != 0 {
+				container.Timeout = d + ContainerTimeoutGrace
 			}
+			container.Action = c.runStep(d, bin, src, state, cache, path, step)
 
-			if _, err := runner.ExitCode(ctx); err != nil {
-				return err
-			}
+			wg.Add(container, pipeline.ActionOpts{
+				Logger: log,
+				Step:   step,
+			})
+		}
+
+		return wg.Wait(ctx)
+	}
+}
+
+// stateKey returns the key used to encrypt secrets in the state as a Dagger secret, so that it is not stored in the container's configuration or logs.
+// Like the Scribe CLI, the 'SCRIBE_STATE_KEY' environment variable is used before the file provided with the '-state-key-file' argument.
+// If neither is provided, then nil is returned.
+func (c *Client) stateKey(d *dagger.Client) *dagger.Secret {
+	if os.Getenv("SCRIBE_STATE_KEY") != "" {
+		return d.Host().EnvVariable("SCRIBE_STATE_KEY").Secret()
+	}
+
+	if f := c.Opts.Args.StateKeyFile; f != "" {
+		return d.Host().Directory(filepath.Dir(f)).File(filepath.Base(f)).Secret()
+	}
+
+	return nil
+}
+
+// stepArgs returns the values provided with the '-arg' flag for the arguments that the step requires, as the container does not share the state of this process.
+// Every value is passed as it was provided, so string slices, string maps, and JSON values keep their format. Secrets are not passed, as the command is logged.
+func (c *Client) stepArgs(step pipeline.Step) args.ArgMap {
+	m := args.ArgMap{}
+	for _, arg := range step.Arguments {
+		if arg.Type == state.ArgumentTypeSecret {
+			continue
+		}
+
+		if val, ok := c.Opts.Args.ArgMap[arg.Key]; ok {
+			m[arg.Key] = val
+		}
+	}
+
+	return m
+}
+
+// runStep returns an action that runs the step in a new container using the compiled pipeline.
+func (c *Client) runStep(d *dagger.Client, bin *dagger.Directory, src *dagger.Directory, state *dagger.CacheVolume, cache *dagger.CacheVolume, path string, step pipeline.Step) pipeline.Action {
+	return func(ctx context.Context, opts pipeline.ActionOpts) error {
+		log := opts.Logger
+		log.Infoln("Running steps using dagger client...")
+		binPath := "/opt/scribe/pipeline"
+		runner := d.Container().From(step.Image).
+			WithMountedDirectory("/opt/scribe", bin).
+			WithMountedDirectory("/var/scribe", src).
+			WithMountedCache("/var/scribe-state", state, dagger.ContainerWithMountedCacheOpts{}).
+			WithMountedCache("/var/scribe-cache", cache, dagger.ContainerWithMountedCacheOpts{}).
+			WithEntrypoint([]string{}).
+			WithWorkdir("/var/scribe")
+
+		// Every container shares the state, so every container has to use the same key to encrypt and decrypt its secrets.
+		if key := c.stateKey(d); key != nil {
+			runner = runner.WithSecretVariable("SCRIBE_STATE_KEY", key)
+		}
+
+		cmd, err := cmdutil.StepCommand(cmdutil.CommandOpts{
+			CompiledPipeline: binPath,
+			Step:             step,
+			PipelineArgs: args.PipelineArgs{
+				Path: path,
+				// Every container shares the 'scribe-state' cache volume, so the build ID is required to use the same state file.
+				BuildID: c.Opts.Args.BuildID,
+				State:   "file:///var/scribe-state/state.json",
+				Cache:   "file:///var/scribe-cache",
+				ArgMap:  c.stepArgs(step),
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		// Some containers have entrypoints that can make `Exec` inconsistent. This attempts to disable / override that behavior.
+		//runner = runner.WithEntrypoint([]string{})
+		log.WithField("command", strings.Join(cmd, " ")).Debugln("Registering container with command...")
+		runner = runner.WithExec(cmd)
+
+		if stdout, err := runner.Stderr(ctx); err == nil {
+			log.WithField("stream", "stdout").Infoln(stdout)
+		}
+
+		if stderr, err := runner.Stderr(ctx); err == nil {
+			log.WithField("stream", "stderr").Infoln(stderr)
+		}
+
+		if _, err := runner.ExitCode(ctx); err != nil {
+			return err
 		}
 
 		return nil
@@ -74,7 +165,7 @@ func (c *Client) StepWalkFunc(d *dagger.Client, bin *dagger.Directory, src *dagg
 
 // WalkPipelines is the handler for walking pipelines provided to the pipeline.Walker.
 // It is called once per parallel group of pipelines.
-func (c *Client) PipelineWalkFunc(w pipeline.Walker, d *dagger.Client, cache *dagger.CacheVolume) pipeline.PipelineWalkFunc {
+func (c *Client) PipelineWalkFunc(w pipeline.Walker, d *dagger.Client, state *dagger.CacheVolume, cache *dagger.CacheVolume) pipeline.PipelineWalkFunc {
 	return func(ctx context.Context, pipelines ...pipeline.Pipeline) error {
 		// This is where all of the source code for the project lives, including the pipeline.
 		src, err := c.Opts.State.GetDirectoryString(pipeline.ArgumentSourceFS)
@@ -94,10 +185,17 @@ func (c *Client) PipelineWalkFunc(w pipeline.Walker, d *dagger.Client, cache *da
 			return err
 		}
 
-		wg := syncutil.NewPipelineWaitGroup()
-		for _, pipeline := range pipelines {
-			wf := c.StepWalkFunc(d, bin, d.Host().Directory(src), cache, c.Opts.Args.Path)
-			wg.Add(pipeline, w, wf)
+		wg := syncutil.NewPipelineWaitGroupWithPolicy(c.Opts.FailurePolicy())
+		for _, p := range pipelines {
+			wf := c.StepWalkFunc(d, bin, d.Host().Directory(src), state, cache, c.Opts.Args.Path)
+
+			// Like with the CLI client, sub-pipelines run in the background and are joined once every other pipeline is done (see Done).
+			if p.Type == pipeline.PipelineTypeSub && c.subs != nil {
+				c.subs.Go(p, w, wf)
+				continue
+			}
+
+			wg.Add(p, w, wf)
 		}
 
 		return wg.Wait(ctx)
@@ -115,8 +213,14 @@ func (c *Client) Done(ctx context.Context, w pipeline.Walker) error {
 	}
 	defer d.Close()
 
-	cache := d.CacheVolume("scribe-state")
-	return w.WalkPipelines(ctx, c.PipelineWalkFunc(w, d, cache))
+	state := d.CacheVolume("scribe-state")
+	cache := d.CacheVolume("scribe-cache")
+
+	c.subs, ctx = syncutil.NewSubPipelineGroup(ctx, c.Opts.FailurePolicy(), c.Log)
+	err = w.WalkPipelines(ctx, c.PipelineWalkFunc(w, d, state, cache))
+
+	// The connection is closed when Done returns, so every sub-pipeline has to be done before then.
+	return c.subs.Join(err)
 }
 
 // Validate is ran internally before calling Run or Parallel and allows the client to effectively configure per-step requirements
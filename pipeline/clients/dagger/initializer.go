@@ -7,7 +7,8 @@ import (
 
 func New(opts clients.CommonOpts) pipeline.Client {
 	return &Client{
-		Opts: opts,
-		Log:  opts.Log,
+		Opts:      opts,
+		Log:       opts.Log,
+		scheduler: opts.Scheduler(),
 	}
 }
@@ -2,6 +2,7 @@ package drone
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"path"
 
@@ -22,7 +23,9 @@ var (
 
 // Client is the Drone implementation of the pipeline Client interface.
 // It will create a `.drone.yml` file that will run your pipeline the same way that it would run locally.
-// It uses the dagger client to run an entire pipeline as a single step. While not ideal for visualization purposes, this does behavior is what enables consistency.
+// By default, it uses the dagger client to run an entire pipeline as a single step. While not ideal for visualization purposes, this does behavior is what enables consistency.
+// When the '-drone-mode=step' argument is provided, every step is converted into its own Drone step instead.
+// When the '-drone-language=starlark' argument is provided, a `.drone.star` file is created instead of a `.drone.yml` file.
 type Client struct {
 	Opts clients.CommonOpts
 
@@ -39,6 +42,10 @@ func (c *Client) Validate(step pipeline.Step) error {
 		return ErrorNoName
 	}
 
+	if len(step.Events) != 0 && c.Opts.Args.DroneMode != ModeStep {
+		return clients.StepEventsError("Use the '-drone-mode=step' argument to convert the step's events into 'when' conditions.")
+	}
+
 	return nil
 }
 
@@ -78,13 +85,62 @@ func (c *Client) Step(v pipeline.Pipeline, state string) (*yaml.Container, error
 	if err != nil {
 		return nil, err
 	}
+	c.withStateKey(step)
 	return step, nil
 }
 
+// withStateKey adds the key used to encrypt secrets in the state to the step's environment from the Drone secret with the same name, if a key was provided to the generator.
+func (c *Client) withStateKey(step *yaml.Container) {
+	if !clients.StateKeyProvided(c.Opts) {
+		return
+	}
+
+	if step.Environment == nil {
+		step.Environment = map[string]*yaml.Variable{}
+	}
+
+	step.Environment[clients.StateKeyEnv] = &yaml.Variable{
+		Secret: clients.StateKeyEnv,
+	}
+}
+
+// pipelineSteps converts every step in the pipeline into its own Drone step.
+// Background steps without an action are converted into services.
+func (c *Client) pipelineSteps(ctx context.Context, w pipeline.Walker, p pipeline.Pipeline, state string) (*stepList, error) {
+	list := &stepList{}
+
+	err := w.WalkSteps(ctx, p.ID, func(ctx context.Context, steps ...pipeline.Step) error {
+		for _, v := range steps {
+			if v.IsBackground() && v.Action == nil {
+				list.AddService(NewService(c, v))
+				continue
+			}
+
+			step, err := NewStep(c, c.Opts.Args.Path, state, c.Opts.Version, v)
+			if err != nil {
+				return err
+			}
+			c.withStateKey(step)
+
+			list.AddStep(step)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
 var (
-	PipelinePath = "/var/scribe/pipeline"
-	StatePath    = "/var/scribe-state"
-	ScribeVolume = &yaml.Volume{
+	// CompileStepName is the name of the step that compiles the pipeline. It is the first step in every Drone pipeline.
+	CompileStepName = "builtin-compile-pipeline"
+	PipelinePath    = "/var/scribe/pipeline"
+	StatePath       = "/var/scribe-state"
+	ScribeVolume    = &yaml.Volume{
 		Name:     "scribe",
 		EmptyDir: &yaml.VolumeEmptyDir{},
 	}
@@ -122,7 +178,7 @@ func (c *Client) newPipeline(opts newPipelineOpts, pipelineOpts clients.CommonOp
 	})
 
 	build := &yaml.Container{
-		Name:    "builtin-compile-pipeline",
+		Name:    CompileStepName,
 		Image:   "golang:1.19",
 		Command: command.Args,
 		Environment: map[string]*yaml.Variable{
@@ -166,28 +222,57 @@ func (c *Client) newPipeline(opts newPipelineOpts, pipelineOpts clients.CommonOp
 
 // Done traverses through the tree and writes a .drone.yml file to the provided writer
 func (c *Client) Done(ctx context.Context, w pipeline.Walker) error {
-	cfg := []yaml.Resource{}
+	cfg := []*yaml.Pipeline{}
 	log := c.Log.WithField("client", "drone")
 
+	if err := clients.WarnArguments(ctx, w, c.Opts, log, c.knownArgument); err != nil {
+		return err
+	}
+
+	mode := c.Opts.Args.DroneMode
+	if mode == "" {
+		mode = ModePipeline
+	}
+
+	if mode != ModePipeline && mode != ModeStep {
+		return fmt.Errorf("unknown drone mode '%s'; expected '%s' or '%s'", mode, ModePipeline, ModeStep)
+	}
+
+	language, err := ParseLanguage(c.Opts.Args.DroneLanguage)
+	if err != nil {
+		return err
+	}
+
 	// StatePath is an aboslute path and already has a '/'.
 	state := &url.URL{
 		Scheme: "file",
 		Path:   path.Join(StatePath, "state.json"),
 	}
 
-	err := w.WalkPipelines(ctx, func(ctx context.Context, pipelines ...pipeline.Pipeline) error {
+	err = w.WalkPipelines(ctx, func(ctx context.Context, pipelines ...pipeline.Pipeline) error {
 		log.Debugf("Walking '%d' pipelines...", len(pipelines))
 		for _, v := range pipelines {
 			log.Debugf("Processing pipeline '%s'...", v.Name)
 
-			s, err := c.Step(v, state.String())
-			if err != nil {
-				return err
+			steps := &stepList{}
+			if mode == ModeStep {
+				list, err := c.pipelineSteps(ctx, w, v, state.String())
+				if err != nil {
+					return err
+				}
+				steps = list
+			} else {
+				s, err := c.Step(v, state.String())
+				if err != nil {
+					return err
+				}
+				steps.AddStep(s)
 			}
 
 			pipeline := c.newPipeline(newPipelineOpts{
 				Name:      stringutil.Slugify(v.Name),
-				Steps:     []*yaml.Container{s},
+				Steps:     steps.steps,
+				Services:  steps.services,
 				DependsOn: pipelinesToNames(v.Dependencies),
 			}, c.Opts)
 			if len(v.Events) == 0 {
@@ -216,8 +301,17 @@ func (c *Client) Done(ctx context.Context, w pipeline.Walker) error {
 		return err
 	}
 
+	if language == LanguageStarlark {
+		return WriteStarlark(c.Opts.Output, cfg)
+	}
+
+	resources := make([]yaml.Resource, len(cfg))
+	for i, v := range cfg {
+		resources[i] = v
+	}
+
 	manifest := &yaml.Manifest{
-		Resources: cfg,
+		Resources: resources,
 	}
 	pretty.Print(c.Opts.Output, manifest)
 
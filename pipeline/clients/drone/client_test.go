@@ -7,12 +7,17 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/scribe"
 	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients"
 	"github.com/grafana/scribe/pipeline/clients/drone"
+	"github.com/grafana/scribe/state"
 	"github.com/grafana/scribe/testutil"
 	"github.com/sirupsen/logrus"
 )
@@ -22,6 +27,12 @@ import (
 // Some standard arguments will be provided, like "-mode=drone", '-build-id="test"', "-path={path}", -log-level="debug".
 func testDemoPipeline(t *testing.T, path string) {
 	t.Helper()
+	testDemoPipelineConfig(t, path, drone.ModePipeline, "yaml", "gen_drone.yml")
+}
+
+// testDemoPipelineConfig is the same as testDemoPipeline, but it generates the pipeline using the provided '-drone-mode' and '-drone-language' and compares it with the 'file' in the provided folder.
+func testDemoPipelineConfig(t *testing.T, path, mode, language, file string) {
+	t.Helper()
 
 	var (
 		buf          = bytes.NewBuffer(nil)
@@ -31,15 +42,17 @@ func testDemoPipeline(t *testing.T, path string) {
 	)
 
 	testutil.RunPipeline(ctx, t, pipelinePath, io.MultiWriter(buf, os.Stdout), stderr, &args.PipelineArgs{
-		BuildID:  "test",
-		Client:   "drone",
-		Path:     fmt.Sprintf("./demo/%s", path), // Note that we're intentionally using ./demo/ instead of filepath because this path is used in a Go command.
-		LogLevel: logrus.DebugLevel,
+		BuildID:       "test",
+		Client:        "drone",
+		Path:          fmt.Sprintf("./demo/%s", path), // Note that we're intentionally using ./demo/ instead of filepath because this path is used in a Go command.
+		LogLevel:      logrus.DebugLevel,
+		DroneMode:     mode,
+		DroneLanguage: language,
 	})
 
 	t.Log(stderr.String())
 
-	expected, err := os.Open(filepath.Join(pipelinePath, "gen_drone.yml"))
+	expected, err := os.Open(filepath.Join(pipelinePath, file))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -68,6 +81,21 @@ func TestDroneClient(t *testing.T) {
 			testDemoPipeline(t, "multi-sub")
 		}),
 	)
+	t.Run("It should generate a Drone step for every step when using the step mode",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipelineConfig(t, "basic", drone.ModeStep, "yaml", "gen_drone_steps.yml")
+		}),
+	)
+	t.Run("It should generate dependencies between parallel steps when using the step mode",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipelineConfig(t, "multi", drone.ModeStep, "yaml", "gen_drone_steps.yml")
+		}),
+	)
+	t.Run("It should generate a Drone Starlark config",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipelineConfig(t, "multi", drone.ModePipeline, "starlark", "gen_drone.star")
+		}),
+	)
 }
 
 func TestDroneRun(t *testing.T) {
@@ -122,3 +150,82 @@ func TestDroneRun(t *testing.T) {
 			}
 		}))
 }
+
+func TestHandleSecrets(t *testing.T) {
+	t.Run("It should pass the arguments that events provide from their Drone environment variables", func(t *testing.T) {
+		step := pipeline.NoOpStep.Requires(
+			pipeline.ArgumentTagName,
+			pipeline.ArgumentPullRequestNumber,
+			pipeline.ArgumentCronName,
+			pipeline.ArgumentCommitSHA,
+		)
+
+		_, args := drone.HandleSecrets(nil, step)
+		expected := map[string]string{
+			"git-tag":             `"$DRONE_TAG"`,
+			"pull-request-number": `"$DRONE_PULL_REQUEST"`,
+			"cron-name":           `"$DRONE_CRON"`,
+		}
+
+		if diff := cmp.Diff(expected, args); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+	t.Run("It should pass structured values provided to the generator with the '-arg' flag", func(t *testing.T) {
+		var (
+			versions = state.NewStringSliceArgument("versions")
+			matrix   = state.NewStringMapArgument("matrix")
+			config   = state.NewJSONArgument("config")
+			missing  = state.NewJSONArgument("missing")
+		)
+
+		client := drone.New(clients.CommonOpts{
+			Args: &args.PipelineArgs{
+				ArgMap: args.ArgMap{
+					"versions": "1.0,2.0",
+					"matrix":   "os=linux",
+					"config":   `{"name": "it's"}`,
+				},
+			},
+		}).(*drone.Client)
+
+		step := pipeline.NoOpStep.Requires(versions, matrix, config, missing)
+
+		_, argMap := drone.HandleSecrets(client, step)
+		expected := map[string]string{
+			"versions": "'1.0,2.0'",
+			"matrix":   "'os=linux'",
+			"config":   `'{"name": "it'"'"'s"}'`,
+		}
+
+		if diff := cmp.Diff(expected, argMap); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+}
+
+func TestStateKey(t *testing.T) {
+	t.Run("The generated config should read the state key from a secret instead of the file provided to the generator", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		opts := clients.CommonOpts{
+			Name:   "test",
+			Output: buf,
+			Log:    logrus.New(),
+			Args: &args.PipelineArgs{
+				Path:         "./ci",
+				StateKeyFile: "/home/user/scribe.key",
+			},
+		}
+
+		col := scribe.NewDefaultCollection(opts)
+		testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, pipeline.NewStepList(1, pipeline.NamedStep("build", pipeline.DefaultAction).WithImage("alpine"))), nil)
+		testutil.EnsureError(t, drone.New(opts).Done(context.Background(), col), nil)
+
+		if strings.Contains(buf.String(), "scribe.key") {
+			t.Fatalf("Expected the path to the state key to not be in the config, but got\n%s", buf.String())
+		}
+		if !strings.Contains(buf.String(), "SCRIBE_STATE_KEY") {
+			t.Fatalf("Expected the state key to be read from a secret, but got\n%s", buf.String())
+		}
+	})
+}
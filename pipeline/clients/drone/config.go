@@ -2,20 +2,48 @@ package drone
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/grafana/scribe/errors"
 	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients/cli"
 	"github.com/grafana/scribe/state"
 )
 
 type DroneLanguage int
 
 const (
-	// The languages that are available when generating a Drone config.
+	// The languages that are available when generating a Drone config, set with the '-drone-language' argument.
 	LanguageYAML DroneLanguage = iota
 	LanguageStarlark
 )
 
+var languageNames = map[string]DroneLanguage{
+	"yaml":     LanguageYAML,
+	"starlark": LanguageStarlark,
+}
+
+// ParseLanguage returns the DroneLanguage with the given name. If the name is empty, then LanguageYAML is returned.
+func ParseLanguage(name string) (DroneLanguage, error) {
+	if name == "" {
+		return LanguageYAML, nil
+	}
+
+	if v, ok := languageNames[name]; ok {
+		return v, nil
+	}
+
+	return LanguageYAML, fmt.Errorf("unknown drone language '%s'; expected 'yaml' or 'starlark'", name)
+}
+
+const (
+	// The modes that are available when generating a Drone config, set with the '-drone-mode' argument.
+	// In ModePipeline, every pipeline is ran as a single Drone step using the CLI client.
+	// In ModeStep, every step in a pipeline becomes its own Drone step.
+	ModePipeline = "pipeline"
+	ModeStep     = "step"
+)
+
 var argVolumeMap = map[state.Argument]string{
 	pipeline.ArgumentDockerSocketFS: "/var/run/docker.sock",
 }
@@ -27,6 +55,17 @@ var argEnvMap = map[state.Argument]string{
 	pipeline.ArgumentWorkingDir: "$DRONE_REPO_NAME",
 }
 
+// eventArgEnvMap holds the equivalent environment variables of the arguments that events provide.
+// The CLI client can not find these values on its own, so they are passed to the generated commands with the '-arg' flag.
+var eventArgEnvMap = map[state.Argument]string{
+	pipeline.ArgumentTagName:           "$DRONE_TAG",
+	pipeline.ArgumentPullRequestNumber: "$DRONE_PULL_REQUEST",
+	pipeline.ArgumentSourceBranch:      "$DRONE_SOURCE_BRANCH",
+	pipeline.ArgumentTargetBranch:      "$DRONE_TARGET_BRANCH",
+	pipeline.ArgumentCronName:          "$DRONE_CRON",
+	pipeline.ArgumentPromoteTarget:     "$DRONE_DEPLOY_TO",
+}
+
 // The configurer for the Drone client returns equivalent environment variables for different arguments.
 func (c *Client) Value(arg state.Argument) (string, error) {
 	switch arg.Type {
@@ -37,11 +76,38 @@ func (c *Client) Value(arg state.Argument) (string, error) {
 			return val, nil
 		}
 		return "", errors.ErrorMissingArgument
+	case state.ArgumentTypeStringSlice, state.ArgumentTypeStringMap, state.ArgumentTypeJSON:
+		// Structured values are not available in the Drone environment, but the ones provided to the generator with the '-arg' flag are passed to the generated commands.
+		if c.Opts.Args != nil {
+			if val, ok := c.Opts.Args.ArgMap[arg.Key]; ok {
+				return shellQuote(val), nil
+			}
+		}
+		return "", fmt.Errorf("could not find equivalent of '%s': %w", arg.Key, errors.ErrorMissingArgument)
 	}
 
 	if val, ok := argEnvMap[arg]; ok {
 		return val, nil
 	}
 
+	if val, ok := eventArgEnvMap[arg]; ok {
+		return val, nil
+	}
+
 	return "", fmt.Errorf("could not find equivalent of '%s': %w", arg.Key, errors.ErrorMissingArgument)
 }
+
+// shellQuote quotes the value in single quotes, so that the JSON values and lists in the generated commands are not changed by the shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
+
+// knownArgument returns true if the value of the argument is available in Drone before any step runs.
+// Generated pipelines are ran with the CLI client, so the arguments that it adds to the state are also known.
+func (c *Client) knownArgument(arg state.Argument) bool {
+	if _, err := c.Value(arg); err == nil {
+		return true
+	}
+
+	return cli.KnownArgument(arg)
+}
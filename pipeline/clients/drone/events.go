@@ -2,40 +2,237 @@ package drone
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp/syntax"
+	"strings"
 
 	"github.com/drone/drone-yaml/yaml"
+	"github.com/grafana/scribe/errors"
 	"github.com/grafana/scribe/pipeline"
 )
 
-// TODO: I'm lazy at the moment and haven't implemented reverse filters (exlude).
-func addEvent(c yaml.Conditions, e pipeline.Event) (yaml.Conditions, error) {
-	if branch, ok := e.Filters["branch"]; ok {
-		c.Event.Include = append(c.Event.Include, "branch")
-		c.Branch.Include = append(c.Branch.Include, branch.String())
+var (
+	ErrorRegexFilter      = errors.NewPipelineError("regex filter can not be converted", "Drone triggers only support glob patterns. Only regular expressions that match a single literal value, like '^main$', can be converted. Use 'pipeline.GlobFilter' instead.")
+	ErrorGlobFilter       = errors.NewPipelineError("invalid glob filter", "The glob pattern could not be parsed. Drone uses the same syntax as 'filepath.Match'.")
+	ErrorUnsupportedEvent = errors.NewPipelineError("unsupported event", "The Drone client supports git commit, git tag, pull request, promote, cron, manual, and custom events.")
+	ErrorConflictingEvent = errors.NewPipelineError("conflicting event filters", "A pull request branch filter applies to the target branch, which can not be combined with git commit events in the same Drone pipeline. Use separate pipelines instead.")
+)
+
+// escapeGlob escapes the characters that have a special meaning in a glob pattern so that the value is matched literally.
+func escapeGlob(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`)
+	return r.Replace(v)
+}
+
+// regexToGlob converts a regular expression that only matches a single literal value, like '^main$', into an equivalent glob pattern.
+// Drone does not support regular expressions, so any other regular expression returns an ErrorRegexFilter.
+func regexToGlob(expr string) (string, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrorRegexFilter, err)
+	}
+
+	re = re.Simplify()
+	if re.Op != syntax.OpConcat || len(re.Sub) != 3 {
+		return "", fmt.Errorf("%w: '%s'", ErrorRegexFilter, expr)
+	}
+
+	var (
+		begin   = re.Sub[0]
+		literal = re.Sub[1]
+		end     = re.Sub[2]
+	)
+
+	if begin.Op != syntax.OpBeginText || end.Op != syntax.OpEndText || literal.Op != syntax.OpLiteral || literal.Flags&syntax.FoldCase != 0 {
+		return "", fmt.Errorf("%w: '%s'", ErrorRegexFilter, expr)
 	}
 
-	if tag, ok := e.Filters["tag"]; ok {
-		c.Event.Include = append(c.Event.Include, "tag")
-		if tag != nil {
-			c.Ref.Include = append(c.Ref.Include, fmt.Sprintf("refs/tags/%s", tag.String()))
+	return escapeGlob(string(literal.Rune)), nil
+}
+
+// Pattern converts the filter value into a Drone glob pattern.
+// String values are escaped so they're matched literally, and glob values are validated. Drone's '*' does not match '/', so '**' is the same as '*'.
+func Pattern(f *pipeline.FilterValue) (string, error) {
+	switch f.Type {
+	case pipeline.FilterValueRegex:
+		return regexToGlob(f.String())
+	case pipeline.FilterValueGlob:
+		v := f.String()
+		if _, err := filepath.Match(v, ""); err != nil {
+			return "", fmt.Errorf("%w: '%s'", ErrorGlobFilter, v)
 		}
+		for strings.Contains(v, "**") {
+			v = strings.ReplaceAll(v, "**", "*")
+		}
+		return v, nil
+	}
+
+	return escapeGlob(f.String()), nil
+}
+
+func addPattern(c yaml.Condition, f *pipeline.FilterValue, prefix string) (yaml.Condition, error) {
+	v, err := Pattern(f)
+	if err != nil {
+		return c, err
+	}
+
+	v = prefix + v
+	if f.Exclude {
+		c.Exclude = append(c.Exclude, v)
+	} else {
+		c.Include = append(c.Include, v)
 	}
 
 	return c, nil
 }
 
+func appendUnique(s []string, v string) []string {
+	for _, value := range s {
+		if value == v {
+			return s
+		}
+	}
+
+	return append(s, v)
+}
+
+// refPatterns are the refs that should be matched by an event with no ref filter if other events in the same pipeline have one.
+// Because Drone's conditions all have to match, an event that is not filtered by ref would otherwise be excluded by the ref filters of the other events.
+var refPatterns = map[string][]string{
+	"push":         {"refs/heads/*"},
+	"tag":          {"refs/tags/*"},
+	"pull_request": {"refs/pull/*/head", "refs/merge-requests/*/head"},
+}
+
+// droneEvents maps the names of Scribe events to Drone events.
+var droneEvents = map[string]string{
+	"git-commit":   "push",
+	"git-tag":      "tag",
+	"pull-request": "pull_request",
+	"promote":      "promote",
+	"cron":         "cron",
+	"manual":       "custom",
+	"custom":       "custom",
+}
+
+// addEvent adds the Drone event and the conditions for the filters of the event.
+// It returns true if the conditions include a ref filter.
+func addEvent(c yaml.Conditions, e pipeline.Event) (yaml.Conditions, bool, error) {
+	event, ok := droneEvents[e.Name]
+	if !ok {
+		return c, false, fmt.Errorf("%w: '%s'", ErrorUnsupportedEvent, e.Name)
+	}
+
+	c.Event.Include = appendUnique(c.Event.Include, event)
+
+	var (
+		ref        = false
+		refInclude = false
+		err        error
+	)
+
+	for key, f := range e.Filters {
+		if f == nil {
+			continue
+		}
+
+		switch {
+		case event == "push" && key == "branch":
+			c.Ref, err = addPattern(c.Ref, f, "refs/heads/")
+			ref, refInclude = true, refInclude || !f.Exclude
+		case event == "tag" && key == "tag":
+			c.Ref, err = addPattern(c.Ref, f, "refs/tags/")
+			ref, refInclude = true, refInclude || !f.Exclude
+		case event == "pull_request" && key == "branch":
+			c.Branch, err = addPattern(c.Branch, f, "")
+		case event == "promote" && key == "target":
+			c.Target, err = addPattern(c.Target, f, "")
+		// Drone cron jobs are created in the repository settings, so only the name of the job can be used in the trigger and the schedule is ignored.
+		case event == "cron" && key == "cron":
+			c.Cron, err = addPattern(c.Cron, f, "")
+		case key == "paths":
+			c.Paths, err = addPattern(c.Paths, f, "")
+		}
+
+		if err != nil {
+			return c, false, err
+		}
+	}
+
+	// If the ref filters of the event only exclude refs, then the event still has to include every other ref of its type.
+	// Otherwise, the includes of the other events in the pipeline would be the only refs that match.
+	if ref && !refInclude {
+		for _, pattern := range refPatterns[event] {
+			c.Ref.Include = appendUnique(c.Ref.Include, pattern)
+		}
+	}
+
+	return c, ref, nil
+}
+
 // Events converts the list of pipeline.Events to a list of drone 'Conditions'.
 // Drone conditions are what prevents pipelines from running whenever certain certain conditions are met, or what runs pipelines only when certain conditions are met.
+// Branch and tag filters are converted into 'ref' conditions so that git commit and git tag events can be combined in a single pipeline.
 func Events(events []pipeline.Event) (yaml.Conditions, error) {
-	conditions := yaml.Conditions{}
+	var (
+		conditions = yaml.Conditions{}
+		unfiltered = []string{}
+		hasRef     = false
+	)
+
 	for _, event := range events {
-		c, err := addEvent(conditions, event)
+		c, ref, err := addEvent(conditions, event)
 		if err != nil {
 			return yaml.Conditions{}, err
 		}
 
+		if ref {
+			hasRef = true
+		} else {
+			unfiltered = append(unfiltered, droneEvents[event.Name])
+		}
+
 		conditions = c
 	}
 
+	if hasRef {
+		for _, v := range unfiltered {
+			for _, pattern := range refPatterns[v] {
+				conditions.Ref.Include = appendUnique(conditions.Ref.Include, pattern)
+			}
+		}
+	}
+
+	if len(conditions.Branch.Include) != 0 || len(conditions.Branch.Exclude) != 0 {
+		for _, v := range conditions.Event.Include {
+			if v == "push" {
+				return yaml.Conditions{}, ErrorConflictingEvent
+			}
+		}
+	}
+
+	return conditions, nil
+}
+
+// StepConditions converts the RunCondition and the events of a step into the Drone 'when' conditions of the step.
+// Conditions added with 'Step.When' can not be converted; they are checked by the Scribe CLI when the step runs.
+func StepConditions(step pipeline.Step) (yaml.Conditions, error) {
+	conditions := yaml.Conditions{}
+	if len(step.Events) != 0 {
+		c, err := Events(step.Events)
+		if err != nil {
+			return yaml.Conditions{}, err
+		}
+
+		conditions = c
+	}
+
+	switch step.RunOn {
+	case pipeline.RunOnFailure:
+		conditions.Status.Include = []string{"failure"}
+	case pipeline.RunAlways:
+		conditions.Status.Include = []string{"success", "failure"}
+	}
+
 	return conditions, nil
 }
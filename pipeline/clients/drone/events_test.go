@@ -0,0 +1,207 @@
+package drone_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients/drone"
+)
+
+func TestEvents(t *testing.T) {
+	t.Run("Branch and tag filters should be combined as ref conditions", func(t *testing.T) {
+		c, err := drone.Events([]pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+				Branch: pipeline.StringFilter("main"),
+			}),
+			pipeline.GitTagEvent(pipeline.GitTagFilters{
+				Name: pipeline.GlobFilter("v*"),
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"push", "tag"}, c.Event.Include); diff != "" {
+			t.Fatal(diff)
+		}
+		if diff := cmp.Diff([]string{"refs/heads/main", "refs/tags/v*"}, c.Ref.Include); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Events without a ref filter should match every ref of their type if another event has a ref filter", func(t *testing.T) {
+		c, err := drone.Events([]pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{}),
+			pipeline.GitTagEvent(pipeline.GitTagFilters{
+				Name: pipeline.GlobFilter("v*"),
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"refs/tags/v*", "refs/heads/*"}, c.Ref.Include); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Excluding filters should be added as excludes", func(t *testing.T) {
+		c, err := drone.Events([]pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+				Branch: pipeline.Not(pipeline.GlobFilter("release-*")),
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"refs/heads/release-*"}, c.Ref.Exclude); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Events with only excluding ref filters should still match every other ref of their type", func(t *testing.T) {
+		c, err := drone.Events([]pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+				Branch: pipeline.Not(pipeline.StringFilter("main")),
+			}),
+			pipeline.GitTagEvent(pipeline.GitTagFilters{
+				Name: pipeline.GlobFilter("v*"),
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"refs/heads/*", "refs/tags/v*"}, c.Ref.Include); diff != "" {
+			t.Fatal(diff)
+		}
+		if diff := cmp.Diff([]string{"refs/heads/main"}, c.Ref.Exclude); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("String filters should be escaped", func(t *testing.T) {
+		v, err := drone.Pattern(pipeline.StringFilter("feature-*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v != `feature-\*` {
+			t.Fatalf("Expected 'feature-\\*' but got '%s'", v)
+		}
+	})
+
+	t.Run("Literal regex filters should be converted", func(t *testing.T) {
+		v, err := drone.Pattern(pipeline.RegexpFilter(regexp.MustCompile("^main$")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v != "main" {
+			t.Fatalf("Expected 'main' but got '%s'", v)
+		}
+	})
+
+	t.Run("Other regex filters should return an error", func(t *testing.T) {
+		_, err := drone.Pattern(pipeline.RegexpFilter(regexp.MustCompile("^v[0-9]+$")))
+		if !errors.Is(err, drone.ErrorRegexFilter) {
+			t.Fatalf("Expected error '%v' but got '%v'", drone.ErrorRegexFilter, err)
+		}
+	})
+
+	t.Run("Promote, cron, manual, and pull request events should be supported", func(t *testing.T) {
+		c, err := drone.Events([]pipeline.Event{
+			pipeline.PullRequestEvent(pipeline.PullRequestFilters{}),
+			pipeline.PromoteEvent(pipeline.PromoteFilters{
+				Target: pipeline.StringFilter("production"),
+			}),
+			pipeline.CronEvent(pipeline.CronFilters{
+				Name:     pipeline.StringFilter("nightly"),
+				Schedule: "0 4 * * *",
+			}),
+			pipeline.ManualEvent(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"pull_request", "promote", "cron", "custom"}, c.Event.Include); diff != "" {
+			t.Fatal(diff)
+		}
+		if diff := cmp.Diff([]string{"production"}, c.Target.Include); diff != "" {
+			t.Fatal(diff)
+		}
+		if diff := cmp.Diff([]string{"nightly"}, c.Cron.Include); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Paths filters should be added as paths conditions", func(t *testing.T) {
+		c, err := drone.Events([]pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+				Paths: pipeline.PathsChanged("docs/*"),
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"docs/*"}, c.Paths.Include); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Unknown events should return an error", func(t *testing.T) {
+		_, err := drone.Events([]pipeline.Event{{Name: "unknown"}})
+		if !errors.Is(err, drone.ErrorUnsupportedEvent) {
+			t.Fatalf("Expected error '%v' but got '%v'", drone.ErrorUnsupportedEvent, err)
+		}
+	})
+}
+
+func TestStepConditions(t *testing.T) {
+	t.Run("Steps that run on failure should only run when the Drone status is 'failure'", func(t *testing.T) {
+		c, err := drone.StepConditions(pipeline.NoOpStep.OnFailure())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"failure"}, c.Status.Include); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Steps that always run should run when the Drone status is 'success' or 'failure'", func(t *testing.T) {
+		c, err := drone.StepConditions(pipeline.NoOpStep.Always())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"success", "failure"}, c.Status.Include); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("The events of a step should be converted into Drone conditions", func(t *testing.T) {
+		c, err := drone.StepConditions(pipeline.NoOpStep.WhenEvent(pipeline.GitTagEvent(pipeline.GitTagFilters{
+			Name: pipeline.GlobFilter("v*"),
+		})))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"tag"}, c.Event.Include); diff != "" {
+			t.Fatal(diff)
+		}
+		if diff := cmp.Diff([]string{"refs/tags/v*"}, c.Ref.Include); diff != "" {
+			t.Fatal(diff)
+		}
+		if len(c.Status.Include) != 0 {
+			t.Fatalf("Expected no status conditions but got '%v'", c.Status.Include)
+		}
+	})
+}
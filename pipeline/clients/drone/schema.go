@@ -29,7 +29,7 @@ func secretEnv(key string) string {
 
 // HandleSecrets handles the different 'Secret' arguments that are defined in the pipeline step.
 // Secrets are given a generated value and placed in the 'environment', not a user-defined one. That value is then used when the pipeline attempts to retrieve the value in the argument.
-// String arguments are already provided in the command line arguments when `cmdutil.StepCommand'
+// Other arguments are passed with the '-arg' flag if their values are known when the config is generated (see addArg).
 func HandleSecrets(c pipeline.Configurer, step pipeline.Step) (map[string]*yaml.Variable, map[string]string) {
 	var (
 		env  = make(map[string]*yaml.Variable)
@@ -44,12 +44,46 @@ func HandleSecrets(c pipeline.Configurer, step pipeline.Step) (map[string]*yaml.
 				Secret: arg.Key,
 			}
 			args[arg.Key] = "$" + name
+		default:
+			addArg(c, args, arg)
 		}
 	}
 
 	return env, args
 }
 
+// addArg adds the value of the argument to the '-arg' flags if it is known when the config is generated.
+// Arguments that events provide are passed from their Drone environment variables (see eventArgEnvMap), quoted as some of them, like the name of a cron job, may contain spaces.
+// String slices, string maps, and JSON values are passed from the '-arg' flags provided to the generator.
+func addArg(c pipeline.Configurer, args map[string]string, arg state.Argument) {
+	switch arg.Type {
+	case state.ArgumentTypeStringSlice, state.ArgumentTypeStringMap, state.ArgumentTypeJSON:
+		if val, err := c.Value(arg); err == nil {
+			args[arg.Key] = val
+		}
+	default:
+		if val, ok := eventArgEnvMap[arg]; ok {
+			args[arg.Key] = fmt.Sprintf(`"%s"`, val)
+		}
+	}
+}
+
+// pipelineArgs returns the '-arg' flags for the arguments required by the steps in the pipeline whose values are known when the config is generated (see addArg).
+func pipelineArgs(c pipeline.Configurer, p pipeline.Pipeline) map[string]string {
+	args := make(map[string]string)
+	for _, node := range p.Graph.Nodes {
+		for _, step := range node.Value.Steps {
+			for _, arg := range step.Arguments {
+				if arg.Type != state.ArgumentTypeSecret {
+					addArg(c, args, arg)
+				}
+			}
+		}
+	}
+
+	return args
+}
+
 func stepVolumes(c pipeline.Configurer, step pipeline.Step) []*yaml.VolumeMount {
 	volumes := []*yaml.VolumeMount{}
 	// TODO: It's unlikely that we want to actually associate volume mounts with "FS" type arguments.
@@ -64,12 +98,11 @@ func stepVolumes(c pipeline.Configurer, step pipeline.Step) []*yaml.VolumeMount
 			continue
 		}
 
-		// If it's a known argument...
-		value, _ := c.Value(v)
-		//if err != nil {
-		// Skip this then because it's not known. It should be provided by a different step ran previously.
-		// TODO: handle FS type arguments here?
-		//}
+		// If it's not a known argument, then skip it, because it should be provided by a different step ran previously.
+		value, err := c.Value(v)
+		if err != nil {
+			continue
+		}
 
 		volumes = append(volumes, &yaml.VolumeMount{
 			Name:      stringutil.Slugify(v.Key),
@@ -97,10 +130,10 @@ func NewDaggerStep(c pipeline.Configurer, path, state, version string, p pipelin
 		CommandOpts: cmdutil.CommandOpts{
 			CompiledPipeline: PipelinePath,
 			PipelineArgs: args.PipelineArgs{
-				Path:    path,
-				BuildID: "$DRONE_BUILD_NUMBER",
-				State:   state,
-				//ArgMap:        args,
+				Path:     path,
+				BuildID:  "$DRONE_BUILD_NUMBER",
+				State:    state,
+				ArgMap:   pipelineArgs(c, p),
 				Client:   "cli",
 				LogLevel: logrus.DebugLevel,
 				Version:  version,
@@ -118,3 +151,75 @@ func NewDaggerStep(c pipeline.Configurer, path, state, version string, p pipelin
 		Commands: []string{strings.Join(cmd, " ")},
 	}, nil
 }
+
+// NewStep creates a Drone step that runs a single pipeline step using the compiled pipeline and the CLI client.
+// The Drone step uses the image of the pipeline step, and secrets and volumes are added for the arguments that it requires.
+// Background steps that have no action should instead be added as a service with NewService.
+func NewStep(c pipeline.Configurer, path, state, version string, step pipeline.Step) (*yaml.Container, error) {
+	var (
+		name    = stringutil.Slugify(step.Name)
+		deps    = make([]string, len(step.Dependencies))
+		volumes = stepVolumes(c, step)
+	)
+
+	env, argMap := HandleSecrets(c, step)
+
+	for i, v := range step.Dependencies {
+		deps[i] = stringutil.Slugify(v.Name)
+	}
+
+	cmd, err := cmdutil.StepCommand(cmdutil.CommandOpts{
+		Step:             step,
+		CompiledPipeline: PipelinePath,
+		PipelineArgs: args.PipelineArgs{
+			Path:     path,
+			BuildID:  "$DRONE_BUILD_NUMBER",
+			State:    state,
+			ArgMap:   argMap,
+			Client:   "cli",
+			LogLevel: logrus.DebugLevel,
+			Version:  version,
+		},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	when, err := StepConditions(step)
+	if err != nil {
+		return nil, err
+	}
+
+	// Steps that are allowed to fail do not fail the Drone pipeline either.
+	failure := ""
+	if step.FailureAllowed {
+		failure = "ignore"
+	}
+
+	return &yaml.Container{
+		Name:        name,
+		Image:       step.Image,
+		Failure:     failure,
+		Commands:    []string{strings.Join(cmd, " ")},
+		Environment: env,
+		Volumes:     volumes,
+		DependsOn:   deps,
+		// Background steps that have an action can't be services because services start before the pipeline is compiled.
+		// Instead, they are detached steps, which continue running in the background.
+		Detach: step.IsBackground(),
+		When:   when,
+	}, nil
+}
+
+// NewService creates a Drone service from a background step that has no action, which runs the default command of the step's image.
+func NewService(c pipeline.Configurer, step pipeline.Step) *yaml.Container {
+	env, _ := HandleSecrets(c, step)
+
+	return &yaml.Container{
+		Name:        stringutil.Slugify(step.Name),
+		Image:       step.Image,
+		Environment: env,
+		Volumes:     stepVolumes(c, step),
+	}
+}
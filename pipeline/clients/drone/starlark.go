@@ -0,0 +1,198 @@
+package drone
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/drone/drone-yaml/yaml"
+	"github.com/drone/drone-yaml/yaml/pretty"
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// starlarkCall is a value that is written as a call to a function defined in the same Starlark file, like 'scribe_volumes()'.
+type starlarkCall string
+
+var starlarkIdentifier = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// starlarkFunctionName converts the name of a pipeline into a valid Starlark function name.
+func starlarkFunctionName(name string) string {
+	return "pipeline_" + starlarkIdentifier.ReplaceAllString(name, "_")
+}
+
+// decodePipelines converts the pipelines into ordered maps by printing them as YAML with the pretty printer and decoding the result.
+// This ensures that the Starlark output is equivalent to the YAML output.
+func decodePipelines(pipelines []*yaml.Pipeline) ([]yamlv2.MapSlice, error) {
+	resources := make([]yaml.Resource, len(pipelines))
+	for i, v := range pipelines {
+		resources[i] = v
+	}
+
+	buf := &bytes.Buffer{}
+	pretty.Print(buf, &yaml.Manifest{
+		Resources: resources,
+	})
+
+	var (
+		dec    = yamlv2.NewDecoder(buf)
+		values = []yamlv2.MapSlice{}
+	)
+
+	for {
+		v := yamlv2.MapSlice{}
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		if len(v) == 0 {
+			continue
+		}
+
+		values = append(values, v)
+	}
+
+	if len(values) != len(pipelines) {
+		return nil, fmt.Errorf("expected '%d' pipelines but found '%d'", len(pipelines), len(values))
+	}
+
+	return values, nil
+}
+
+func writeStarlarkIndent(w *strings.Builder, indent int) {
+	w.WriteString(strings.Repeat(" ", indent*4))
+}
+
+func writeStarlarkValue(w *strings.Builder, v any, indent int) {
+	switch v := v.(type) {
+	case starlarkCall:
+		w.WriteString(string(v))
+	case yamlv2.MapSlice:
+		if len(v) == 0 {
+			w.WriteString("{}")
+			return
+		}
+		w.WriteString("{\n")
+		for _, item := range v {
+			writeStarlarkIndent(w, indent+1)
+			w.WriteString(strconv.Quote(fmt.Sprint(item.Key)))
+			w.WriteString(": ")
+			writeStarlarkValue(w, item.Value, indent+1)
+			w.WriteString(",\n")
+		}
+		writeStarlarkIndent(w, indent)
+		w.WriteString("}")
+	case []any:
+		if len(v) == 0 {
+			w.WriteString("[]")
+			return
+		}
+		w.WriteString("[\n")
+		for _, value := range v {
+			writeStarlarkIndent(w, indent+1)
+			writeStarlarkValue(w, value, indent+1)
+			w.WriteString(",\n")
+		}
+		writeStarlarkIndent(w, indent)
+		w.WriteString("]")
+	case string:
+		w.WriteString(strconv.Quote(v))
+	case bool:
+		if v {
+			w.WriteString("True")
+		} else {
+			w.WriteString("False")
+		}
+	case int, int64, float64:
+		fmt.Fprint(w, v)
+	case nil:
+		w.WriteString("None")
+	default:
+		w.WriteString(strconv.Quote(fmt.Sprint(v)))
+	}
+}
+
+func writeStarlarkFunction(w *strings.Builder, name string, params string, v any) {
+	fmt.Fprintf(w, "def %s(%s):\n", name, params)
+	writeStarlarkIndent(w, 1)
+	w.WriteString("return ")
+	writeStarlarkValue(w, v, 1)
+	w.WriteString("\n\n")
+}
+
+func isCompileStep(v any) bool {
+	step, ok := v.(yamlv2.MapSlice)
+	if !ok {
+		return false
+	}
+
+	for _, item := range step {
+		if item.Key == "name" {
+			return item.Value == CompileStepName
+		}
+	}
+
+	return false
+}
+
+// WriteStarlark writes the pipelines to 'w' as a Drone Starlark config (.drone.star).
+// Every pipeline is defined in its own function, and the compile step and volumes, which are the same in every pipeline, are defined in shared helper functions.
+// The 'main' function returns the list of every pipeline.
+func WriteStarlark(w io.Writer, pipelines []*yaml.Pipeline) error {
+	values, err := decodePipelines(pipelines)
+	if err != nil {
+		return fmt.Errorf("error converting pipelines for starlark: %w", err)
+	}
+
+	var (
+		buf     = &strings.Builder{}
+		calls   = make([]any, len(values))
+		helpers = yamlv2.MapSlice{}
+	)
+
+	for i, p := range values {
+		for j, item := range p {
+			switch item.Key {
+			case "steps":
+				steps, _ := item.Value.([]any)
+				for k, step := range steps {
+					if isCompileStep(step) {
+						helpers = append(helpers, yamlv2.MapItem{Key: "compile_pipeline_step", Value: step})
+						steps[k] = starlarkCall("compile_pipeline_step()")
+					}
+				}
+			case "volumes":
+				helpers = append(helpers, yamlv2.MapItem{Key: "scribe_volumes", Value: item.Value})
+				p[j].Value = starlarkCall("scribe_volumes()")
+			}
+		}
+
+		calls[i] = starlarkCall(starlarkFunctionName(pipelines[i].Name) + "()")
+	}
+
+	// Every pipeline has the same compile step and volumes, so the helper functions only need to be written once.
+	written := map[any]bool{}
+	for _, v := range helpers {
+		if written[v.Key] {
+			continue
+		}
+
+		writeStarlarkFunction(buf, v.Key.(string), "", v.Value)
+		written[v.Key] = true
+	}
+
+	for i, p := range values {
+		writeStarlarkFunction(buf, starlarkFunctionName(pipelines[i].Name), "", p)
+	}
+
+	writeStarlarkFunction(buf, "main", "ctx", calls)
+
+	_, err = io.WriteString(w, strings.TrimSuffix(buf.String(), "\n"))
+	return err
+}
@@ -0,0 +1,16 @@
+package clients
+
+import (
+	"fmt"
+
+	"github.com/grafana/scribe/errors"
+)
+
+// ErrorStepEvents is returned from the Validate function of clients that run every step in a pipeline in a single CI job or step.
+var ErrorStepEvents = errors.NewPipelineError("step events are not checked", "Every step in the pipeline runs in a single job, so the events of steps added with '.WhenEvent(...)' are not known when the step runs and the step always runs.")
+
+// StepEventsError returns ErrorStepEvents with a hint on how to run the step only for its events with the client.
+// The error wraps errors.ErrorSkipValidation, so it is logged instead of stopping the pipeline.
+func StepEventsError(hint string) error {
+	return fmt.Errorf("%w: %s %s", errors.ErrorSkipValidation, ErrorStepEvents, hint)
+}
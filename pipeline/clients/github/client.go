@@ -0,0 +1,190 @@
+package github
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/grafana/scribe/errors"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/grafana/scribe/pipelineutil"
+	"github.com/grafana/scribe/stringutil"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	ErrorNoName = errors.NewPipelineError("no name provided", "A name is required for all steps in GitHub Actions. You can specify one with the '.WithName(\"name\")' function.")
+)
+
+var (
+	// PipelinePath is where the pipeline is compiled to in every job. It is expanded by the shell on the runner.
+	PipelinePath = "$RUNNER_TEMP/scribe/pipeline"
+	// StatePath is where the state is stored in every job. Like in Drone, the state is not shared between jobs.
+	StatePath = "$RUNNER_TEMP/scribe-state"
+	// GoVersion is the version of Go that is installed to compile the pipeline.
+	GoVersion = "1.19"
+	// RunsOn is the type of machine that every job runs on.
+	RunsOn = "ubuntu-latest"
+)
+
+// Client is the GitHub Actions implementation of the pipeline Client interface.
+// It will create a workflow file that will run your pipeline the same way that it would run locally.
+// Every pipeline is converted into a job that compiles the pipeline and runs it with the CLI client.
+// The output of this client should be placed in the `.github/workflows` folder.
+type Client struct {
+	Opts clients.CommonOpts
+
+	Log *logrus.Logger
+}
+
+// Validate ensures that your step has a name.
+// Images are not required because steps run directly on the GitHub Actions runner.
+func (c *Client) Validate(step pipeline.Step) error {
+	if step.Name == "" {
+		return ErrorNoName
+	}
+
+	if len(step.Events) != 0 {
+		return clients.StepEventsError("Use a separate pipeline with '.When(...)' instead.")
+	}
+
+	return nil
+}
+
+func pipelinesToNames(steps []pipeline.Pipeline) []string {
+	s := make([]string, len(steps))
+	for i, v := range steps {
+		s[i] = stringutil.Slugify(v.Name)
+	}
+
+	return s
+}
+
+func (c *Client) newJob(p pipeline.Pipeline) (*Job, error) {
+	command := pipelineutil.GoBuild(context.Background(), pipelineutil.GoBuildOpts{
+		Pipeline: c.Opts.Args.Path,
+		Output:   PipelinePath,
+	})
+
+	run, err := NewRunStep(c.Opts.Args.Path, "file://"+StatePath+"/state.json", c.Opts.Version, p)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key used to encrypt secrets in the state is read from the repository's secrets, as the file provided to the generator is not on the runner.
+	if clients.StateKeyProvided(c.Opts) {
+		run.Env[clients.StateKeyEnv] = "${{ secrets." + clients.StateKeyEnv + " }}"
+	}
+
+	return &Job{
+		Name:   p.Name,
+		RunsOn: RunsOn,
+		Needs:  pipelinesToNames(p.Dependencies),
+		// Each step's timeout is enforced by the pipeline; the job's timeout is only set when every step has one.
+		TimeoutMinutes: int(math.Ceil(clients.JobTimeout(p).Minutes())),
+		Steps: []Step{
+			{
+				Name: "checkout",
+				Uses: "actions/checkout@v3",
+			},
+			{
+				Name: "setup-go",
+				Uses: "actions/setup-go@v3",
+				With: map[string]string{
+					"go-version": GoVersion,
+				},
+			},
+			{
+				Name: "builtin-compile-pipeline",
+				Env: map[string]string{
+					"CGO_ENABLED": "0",
+				},
+				Run: strings.Join(command.Args, " "),
+			},
+			run,
+		},
+	}, nil
+}
+
+// Done traverses through the tree and writes a GitHub Actions workflow to the provided writer.
+func (c *Client) Done(ctx context.Context, w pipeline.Walker) error {
+	var (
+		log      = c.Log.WithField("client", "github")
+		jobs     = yaml.MapSlice{}
+		triggers = []Triggers{}
+	)
+
+	if err := clients.WarnArguments(ctx, w, c.Opts, log, c.knownArgument); err != nil {
+		return err
+	}
+
+	err := w.WalkPipelines(ctx, func(ctx context.Context, pipelines ...pipeline.Pipeline) error {
+		log.Debugf("Walking '%d' pipelines...", len(pipelines))
+		for _, v := range pipelines {
+			log.Debugf("Processing pipeline '%s'...", v.Name)
+			job, err := c.newJob(v)
+			if err != nil {
+				return err
+			}
+
+			t, err := Events(v.Events)
+			if err != nil {
+				return err
+			}
+
+			triggers = append(triggers, t)
+			jobs = append(jobs, yaml.MapItem{
+				Key:   stringutil.Slugify(v.Name),
+				Value: job,
+			})
+			log.Debugf("Done processing pipeline '%s'", v.Name)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// If every pipeline runs on the same events, then there is no need for job conditions.
+	on := MergeTriggers(triggers...)
+	if len(triggers) != 0 {
+		on = triggers[0]
+	}
+
+	for i := range triggers {
+		if reflect.DeepEqual(triggers[i], triggers[0]) {
+			continue
+		}
+
+		on = MergeTriggers(triggers...)
+
+		for j, t := range triggers {
+			cond, err := Condition(t)
+			if err != nil {
+				return err
+			}
+
+			jobs[j].Value.(*Job).If = cond
+		}
+		break
+	}
+
+	// Multi-pipelines do not have a name, so the name of the folder that contains the pipeline is used instead.
+	name := c.Opts.Name
+	if name == "" {
+		name = filepath.Base(c.Opts.Args.Path)
+	}
+
+	workflow := &Workflow{
+		Name: name,
+		On:   on,
+		Jobs: jobs,
+	}
+
+	return yaml.NewEncoder(c.Opts.Output).Encode(workflow)
+}
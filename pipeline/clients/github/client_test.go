@@ -0,0 +1,120 @@
+package github_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/scribe"
+	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/grafana/scribe/pipeline/clients/github"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+// testDemoPipeline tests a pipeline located in "demo" folder. the "path" argument should be relative to the demo folder in the root of the project.
+// This function will do a basic equivalency check on what is generated by running the pipeline with the github client and what is in the "gen_github.yml" file in the provided folder.
+func testDemoPipeline(t *testing.T, path string) {
+	t.Helper()
+
+	var (
+		buf          = bytes.NewBuffer(nil)
+		stderr       = bytes.NewBuffer(nil)
+		ctx          = context.Background()
+		pipelinePath = filepath.Join("../../../demo", path)
+	)
+
+	testutil.RunPipeline(ctx, t, pipelinePath, io.MultiWriter(buf, os.Stdout), stderr, &args.PipelineArgs{
+		BuildID:  "test",
+		Client:   "github",
+		Path:     fmt.Sprintf("./demo/%s", path), // Note that we're intentionally using ./demo/ instead of filepath because this path is used in a Go command.
+		LogLevel: logrus.DebugLevel,
+	})
+
+	t.Log(stderr.String())
+
+	expected, err := os.Open(filepath.Join(pipelinePath, "gen_github.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.ReadersEqual(t, buf, expected)
+}
+
+func TestGitHubClient(t *testing.T) {
+	t.Run("It should generate a simple GitHub Actions workflow",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "basic")
+		}),
+	)
+	t.Run("It should generate a workflow with multiple jobs and job conditions",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "multi")
+		}),
+	)
+	t.Run("It should generate a workflow with a sub-pipeline",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "sub")
+		}),
+	)
+}
+
+func TestHandleSecrets(t *testing.T) {
+	t.Run("It should pass the arguments that events provide from their GitHub equivalents", func(t *testing.T) {
+		step := pipeline.NoOpStep.Requires(
+			pipeline.ArgumentSourceBranch,
+			pipeline.ArgumentPullRequestNumber,
+			pipeline.ArgumentCommitSHA,
+		)
+
+		p := pipeline.New("test", 1)
+		if err := p.Graph.AddNode(1, pipeline.NewStepList(1, step)); err != nil {
+			t.Fatal(err)
+		}
+
+		_, args := github.HandleSecrets(p)
+		expected := map[string]string{
+			"pull-request-source-branch": `"$GITHUB_HEAD_REF"`,
+			"pull-request-number":        `"${{ github.event.pull_request.number }}"`,
+		}
+
+		if diff := cmp.Diff(expected, args); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+}
+
+func TestStateKey(t *testing.T) {
+	t.Run("The generated config should read the state key from a secret instead of the file provided to the generator", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		opts := clients.CommonOpts{
+			Name:   "test",
+			Output: buf,
+			Log:    logrus.New(),
+			Args: &args.PipelineArgs{
+				Path:         "./ci",
+				StateKeyFile: "/home/user/scribe.key",
+			},
+		}
+
+		col := scribe.NewDefaultCollection(opts)
+		testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, pipeline.NewStepList(1, pipeline.NamedStep("build", pipeline.DefaultAction).WithImage("alpine"))), nil)
+		testutil.EnsureError(t, github.New(opts).Done(context.Background(), col), nil)
+
+		if strings.Contains(buf.String(), "scribe.key") {
+			t.Fatalf("Expected the path to the state key to not be in the config, but got\n%s", buf.String())
+		}
+		if !strings.Contains(buf.String(), "SCRIBE_STATE_KEY") {
+			t.Fatalf("Expected the state key to be read from a secret, but got\n%s", buf.String())
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package github
+
+import (
+	"fmt"
+
+	"github.com/grafana/scribe/errors"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients/cli"
+	"github.com/grafana/scribe/state"
+)
+
+var argEnvMap = map[state.Argument]string{
+	pipeline.ArgumentCommitSHA:  "$GITHUB_SHA",
+	pipeline.ArgumentCommitRef:  "$GITHUB_REF",
+	pipeline.ArgumentBranch:     "$GITHUB_REF_NAME",
+	pipeline.ArgumentRemoteURL:  "$GITHUB_SERVER_URL/$GITHUB_REPOSITORY",
+	pipeline.ArgumentWorkingDir: "$GITHUB_WORKSPACE",
+}
+
+// eventArgEnvMap holds the equivalent values of the arguments that events provide.
+// The CLI client can not find these values on its own, so they are passed to the generated commands with the '-arg' flag.
+var eventArgEnvMap = map[state.Argument]string{
+	pipeline.ArgumentTagName:           "$GITHUB_REF_NAME",
+	pipeline.ArgumentPullRequestNumber: "${{ github.event.pull_request.number }}",
+	pipeline.ArgumentSourceBranch:      "$GITHUB_HEAD_REF",
+	pipeline.ArgumentTargetBranch:      "$GITHUB_BASE_REF",
+	pipeline.ArgumentCronName:          "${{ github.event.schedule }}",
+	pipeline.ArgumentPromoteTarget:     "${{ github.event.deployment.environment }}",
+}
+
+// The configurer for the GitHub client returns equivalent environment variables for different arguments.
+func (c *Client) Value(arg state.Argument) (string, error) {
+	switch arg.Type {
+	case state.ArgumentTypeSecret:
+		return "$" + secretEnv(arg.Key), nil
+	case state.ArgumentTypeUnpackagedFS:
+		if arg == pipeline.ArgumentDockerSocketFS {
+			return "/var/run/docker.sock", nil
+		}
+		return "", errors.ErrorMissingArgument
+	}
+
+	if val, ok := argEnvMap[arg]; ok {
+		return val, nil
+	}
+
+	if val, ok := eventArgEnvMap[arg]; ok {
+		return val, nil
+	}
+
+	return "", fmt.Errorf("could not find equivalent of '%s': %w", arg.Key, errors.ErrorMissingArgument)
+}
+
+// knownArgument returns true if the value of the argument is available in GitHub Actions before any step runs.
+// Generated pipelines are ran with the CLI client, so the arguments that it adds to the state are also known.
+func (c *Client) knownArgument(arg state.Argument) bool {
+	if _, err := c.Value(arg); err == nil {
+		return true
+	}
+
+	return cli.KnownArgument(arg)
+}
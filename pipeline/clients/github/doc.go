@@ -0,0 +1,2 @@
+// Package github contains the GitHub Actions client implementation for generating a GitHub Actions workflow.
+package github
@@ -0,0 +1,308 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/scribe/errors"
+	"github.com/grafana/scribe/pipeline"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	ErrorRegexFilter      = errors.NewPipelineError("regex filters are not supported", "GitHub Actions only supports glob patterns when filtering branches and tags. Use 'pipeline.StringFilter' or 'pipeline.GlobFilter' instead.")
+	ErrorGlobCondition    = errors.NewPipelineError("glob filter can not be used in a job condition", "Pipelines in the same workflow with different events require a job condition, which only supports exact values or a single trailing '*' in glob filters.")
+	ErrorPathsCondition   = errors.NewPipelineError("paths filter can not be used in a job condition", "Pipelines in the same workflow with different events require a job condition, which can not check the files that were changed. Use the same events for every pipeline or remove the paths filter.")
+	ErrorCronSchedule     = errors.NewPipelineError("cron event has no schedule", "GitHub Actions schedules are defined in the workflow. Provide a schedule using 'pipeline.CronFilters{Schedule: ...}'.")
+	ErrorUnsupportedEvent = errors.NewPipelineError("unsupported event", "The GitHub Actions client only supports git commit, git tag, pull request, cron, and manual events.")
+)
+
+// matchAll is the glob pattern that matches every branch or tag.
+const matchAll = "**"
+
+// filterPatterns returns the GitHub patterns for the filter value. Excluding filters are negative patterns, which must follow a positive pattern.
+func filterPatterns(f *pipeline.FilterValue) ([]string, error) {
+	if f == nil {
+		return []string{matchAll}, nil
+	}
+
+	if f.Type == pipeline.FilterValueRegex {
+		return nil, ErrorRegexFilter
+	}
+
+	if f.Exclude {
+		return []string{matchAll, "!" + f.String()}, nil
+	}
+
+	return []string{f.String()}, nil
+}
+
+// refCondition returns a GitHub expression that is true when the context variable, like 'github.ref', matches the filter value.
+func refCondition(variable, prefix, value string) (string, error) {
+	if value == matchAll {
+		return fmt.Sprintf("startsWith(%s, '%s')", variable, prefix), nil
+	}
+
+	glob := strings.TrimSuffix(value, "*")
+	if strings.ContainsAny(glob, "*?[]!+") {
+		return "", ErrorGlobCondition
+	}
+
+	if glob != value {
+		return fmt.Sprintf("startsWith(%s, '%s%s')", variable, prefix, glob), nil
+	}
+
+	return fmt.Sprintf("%s == '%s%s'", variable, prefix, value), nil
+}
+
+func appendUnique(s []string, v string) []string {
+	if slices.Contains(s, v) {
+		return s
+	}
+
+	return append(s, v)
+}
+
+// pathPatterns returns the GitHub patterns for the event's paths filter, or nil if the event is not filtered by paths.
+func pathPatterns(e pipeline.Event) ([]string, error) {
+	if e.Filters["paths"] == nil {
+		return nil, nil
+	}
+
+	return filterPatterns(e.Filters["paths"])
+}
+
+func addEvent(t Triggers, e pipeline.Event) (Triggers, error) {
+	switch e.Name {
+	case "git-commit":
+		branches, err := filterPatterns(e.Filters["branch"])
+		if err != nil {
+			return t, err
+		}
+		paths, err := pathPatterns(e)
+		if err != nil {
+			return t, err
+		}
+		if t.Push == nil {
+			t.Push = &PushTrigger{}
+		}
+		for _, v := range branches {
+			t.Push.Branches = appendUnique(t.Push.Branches, v)
+		}
+		for _, v := range paths {
+			t.Push.Paths = appendUnique(t.Push.Paths, v)
+		}
+	case "git-tag":
+		tags, err := filterPatterns(e.Filters["tag"])
+		if err != nil {
+			return t, err
+		}
+		if t.Push == nil {
+			t.Push = &PushTrigger{}
+		}
+		for _, v := range tags {
+			t.Push.Tags = appendUnique(t.Push.Tags, v)
+		}
+	case "pull-request":
+		paths, err := pathPatterns(e)
+		if err != nil {
+			return t, err
+		}
+		t.PullRequest = &PullRequestTrigger{
+			Paths: paths,
+		}
+		if f := e.Filters["branch"]; f != nil {
+			branches, err := filterPatterns(f)
+			if err != nil {
+				return t, err
+			}
+			t.PullRequest.Branches = branches
+		}
+	case "cron":
+		// GitHub schedules do not have names, so the name filter can not be used.
+		schedule := e.Filters["schedule"]
+		if schedule == nil {
+			return t, ErrorCronSchedule
+		}
+		t.Schedule = append(t.Schedule, ScheduleTrigger{
+			Cron: schedule.String(),
+		})
+	case "manual":
+		t.WorkflowDispatch = &WorkflowDispatchTrigger{}
+	default:
+		return t, fmt.Errorf("%w: '%s'", ErrorUnsupportedEvent, e.Name)
+	}
+
+	return t, nil
+}
+
+// Events converts the list of pipeline.Events to the workflow triggers ('on').
+// A pipeline with no events runs on every push and pull request.
+func Events(events []pipeline.Event) (Triggers, error) {
+	if len(events) == 0 {
+		return Triggers{
+			Push: &PushTrigger{
+				Branches: []string{matchAll},
+				Tags:     []string{matchAll},
+			},
+			PullRequest: &PullRequestTrigger{},
+		}, nil
+	}
+
+	t := Triggers{}
+	for _, event := range events {
+		v, err := addEvent(t, event)
+		if err != nil {
+			return Triggers{}, err
+		}
+
+		t = v
+	}
+
+	return t, nil
+}
+
+// MergeTriggers combines the triggers of every pipeline into the triggers of a single workflow.
+// Negative patterns are not included because they would also exclude refs from the other pipelines; they are handled by the job conditions instead.
+func MergeTriggers(triggers ...Triggers) Triggers {
+	var (
+		t               = Triggers{}
+		allPullRequests = false
+	)
+
+	for _, v := range triggers {
+		if v.Push != nil {
+			if t.Push == nil {
+				t.Push = &PushTrigger{}
+			}
+			for _, b := range v.Push.Branches {
+				if !strings.HasPrefix(b, "!") {
+					t.Push.Branches = appendUnique(t.Push.Branches, b)
+				}
+			}
+			for _, tag := range v.Push.Tags {
+				if !strings.HasPrefix(tag, "!") {
+					t.Push.Tags = appendUnique(t.Push.Tags, tag)
+				}
+			}
+		}
+
+		if v.PullRequest != nil {
+			if t.PullRequest == nil {
+				t.PullRequest = &PullRequestTrigger{}
+			}
+			if len(v.PullRequest.Branches) == 0 {
+				allPullRequests = true
+			}
+			for _, b := range v.PullRequest.Branches {
+				if !strings.HasPrefix(b, "!") {
+					t.PullRequest.Branches = appendUnique(t.PullRequest.Branches, b)
+				}
+			}
+		}
+
+		if v.WorkflowDispatch != nil {
+			t.WorkflowDispatch = &WorkflowDispatchTrigger{}
+		}
+
+		for _, schedule := range v.Schedule {
+			if !slices.Contains(t.Schedule, schedule) {
+				t.Schedule = append(t.Schedule, schedule)
+			}
+		}
+	}
+
+	// A pull request trigger without branches matches every pull request, so the branches of the other pipelines don't matter.
+	if allPullRequests {
+		t.PullRequest.Branches = nil
+	}
+
+	return t
+}
+
+// eventCondition returns a GitHub expression that is true for an event where the context variable matches the patterns.
+// The variable must match one of the positive patterns and none of the negative patterns.
+func eventCondition(event, variable, prefix string, patterns []string) (string, error) {
+	var (
+		include = []string{}
+		exclude = []string{}
+	)
+
+	for _, v := range patterns {
+		if strings.HasPrefix(v, "!") {
+			c, err := refCondition(variable, prefix, strings.TrimPrefix(v, "!"))
+			if err != nil {
+				return "", err
+			}
+			exclude = append(exclude, fmt.Sprintf("!(%s)", c))
+			continue
+		}
+
+		c, err := refCondition(variable, prefix, v)
+		if err != nil {
+			return "", err
+		}
+		include = append(include, c)
+	}
+
+	cond := strings.Join(include, " || ")
+	if len(include) > 1 {
+		cond = fmt.Sprintf("(%s)", cond)
+	}
+
+	if len(exclude) != 0 {
+		cond = strings.Join(append([]string{cond}, exclude...), " && ")
+	}
+
+	return fmt.Sprintf("(github.event_name == '%s' && %s)", event, cond), nil
+}
+
+// Condition converts the triggers of a single pipeline into a job condition ('if').
+// Because every pipeline is a job in the same workflow, the condition prevents a job from running on events that trigger the workflow for other pipelines.
+func Condition(t Triggers) (string, error) {
+	conditions := []string{}
+	if t.Push != nil {
+		if len(t.Push.Paths) != 0 {
+			return "", ErrorPathsCondition
+		}
+		if len(t.Push.Branches) != 0 {
+			c, err := eventCondition("push", "github.ref", "refs/heads/", t.Push.Branches)
+			if err != nil {
+				return "", err
+			}
+			conditions = append(conditions, c)
+		}
+		if len(t.Push.Tags) != 0 {
+			c, err := eventCondition("push", "github.ref", "refs/tags/", t.Push.Tags)
+			if err != nil {
+				return "", err
+			}
+			conditions = append(conditions, c)
+		}
+	}
+
+	if t.PullRequest != nil {
+		if len(t.PullRequest.Paths) != 0 {
+			return "", ErrorPathsCondition
+		}
+		if len(t.PullRequest.Branches) != 0 {
+			c, err := eventCondition("pull_request", "github.base_ref", "", t.PullRequest.Branches)
+			if err != nil {
+				return "", err
+			}
+			conditions = append(conditions, c)
+		} else {
+			conditions = append(conditions, "github.event_name == 'pull_request'")
+		}
+	}
+
+	if t.WorkflowDispatch != nil {
+		conditions = append(conditions, "github.event_name == 'workflow_dispatch'")
+	}
+
+	for _, v := range t.Schedule {
+		conditions = append(conditions, fmt.Sprintf("(github.event_name == 'schedule' && github.event.schedule == '%s')", v.Cron))
+	}
+
+	return strings.Join(conditions, " || "), nil
+}
@@ -0,0 +1,144 @@
+package github_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients/github"
+)
+
+func TestEvents(t *testing.T) {
+	t.Run("Regex filters should return an error", func(t *testing.T) {
+		_, err := github.Events([]pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+				Branch: pipeline.RegexpFilter(regexp.MustCompile("^main$")),
+			}),
+		})
+
+		if !errors.Is(err, github.ErrorRegexFilter) {
+			t.Fatalf("Expected error '%v' but got '%v'", github.ErrorRegexFilter, err)
+		}
+	})
+
+	t.Run("Branch and tag filters should be converted into a job condition", func(t *testing.T) {
+		triggers, err := github.Events([]pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+				Branch: pipeline.StringFilter("main"),
+			}),
+			pipeline.GitTagEvent(pipeline.GitTagFilters{
+				Name: pipeline.GlobFilter("v*"),
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cond, err := github.Condition(triggers)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "(github.event_name == 'push' && github.ref == 'refs/heads/main') || (github.event_name == 'push' && startsWith(github.ref, 'refs/tags/v'))"
+		if cond != expected {
+			t.Fatalf("Expected condition '%s' but got '%s'", expected, cond)
+		}
+	})
+
+	t.Run("Excluding filters should be converted into negative patterns", func(t *testing.T) {
+		triggers, err := github.Events([]pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+				Branch: pipeline.Not(pipeline.GlobFilter("release-*")),
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"**", "!release-*"}, triggers.Push.Branches); diff != "" {
+			t.Fatal(diff)
+		}
+
+		cond, err := github.Condition(triggers)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "(github.event_name == 'push' && startsWith(github.ref, 'refs/heads/') && !(startsWith(github.ref, 'refs/heads/release-')))"
+		if cond != expected {
+			t.Fatalf("Expected condition '%s' but got '%s'", expected, cond)
+		}
+	})
+	t.Run("Cron, manual, and pull request events should be converted into triggers and job conditions", func(t *testing.T) {
+		triggers, err := github.Events([]pipeline.Event{
+			pipeline.PullRequestEvent(pipeline.PullRequestFilters{
+				Branch: pipeline.StringFilter("main"),
+			}),
+			pipeline.CronEvent(pipeline.CronFilters{
+				Schedule: "0 4 * * *",
+			}),
+			pipeline.ManualEvent(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]github.ScheduleTrigger{{Cron: "0 4 * * *"}}, triggers.Schedule); diff != "" {
+			t.Fatal(diff)
+		}
+		if triggers.WorkflowDispatch == nil {
+			t.Fatal("Expected a workflow_dispatch trigger")
+		}
+
+		cond, err := github.Condition(triggers)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "(github.event_name == 'pull_request' && github.base_ref == 'main') || github.event_name == 'workflow_dispatch' || (github.event_name == 'schedule' && github.event.schedule == '0 4 * * *')"
+		if cond != expected {
+			t.Fatalf("Expected condition '%s' but got '%s'", expected, cond)
+		}
+	})
+
+	t.Run("Cron events without a schedule should return an error", func(t *testing.T) {
+		_, err := github.Events([]pipeline.Event{
+			pipeline.CronEvent(pipeline.CronFilters{}),
+		})
+
+		if !errors.Is(err, github.ErrorCronSchedule) {
+			t.Fatalf("Expected error '%v' but got '%v'", github.ErrorCronSchedule, err)
+		}
+	})
+
+	t.Run("Paths filters can not be used in a job condition", func(t *testing.T) {
+		triggers, err := github.Events([]pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+				Paths: pipeline.PathsChanged("docs/**"),
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{"docs/**"}, triggers.Push.Paths); diff != "" {
+			t.Fatal(diff)
+		}
+
+		if _, err := github.Condition(triggers); !errors.Is(err, github.ErrorPathsCondition) {
+			t.Fatalf("Expected error '%v' but got '%v'", github.ErrorPathsCondition, err)
+		}
+	})
+
+	t.Run("Promote events should return an error", func(t *testing.T) {
+		_, err := github.Events([]pipeline.Event{
+			pipeline.PromoteEvent(pipeline.PromoteFilters{}),
+		})
+
+		if !errors.Is(err, github.ErrorUnsupportedEvent) {
+			t.Fatalf("Expected error '%v' but got '%v'", github.ErrorUnsupportedEvent, err)
+		}
+	})
+}
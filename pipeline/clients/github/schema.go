@@ -0,0 +1,142 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/cmdutil"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/stringutil"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// Workflow is a GitHub Actions workflow file, typically placed in `.github/workflows`.
+// See https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions.
+type Workflow struct {
+	Name string   `yaml:"name"`
+	On   Triggers `yaml:"on"`
+
+	// Jobs is a map of job IDs to *Job. A MapSlice is used so that the jobs are written in the order that they are walked.
+	Jobs yaml.MapSlice `yaml:"jobs"`
+}
+
+// Triggers are the events that cause the workflow to run.
+type Triggers struct {
+	Push             *PushTrigger             `yaml:"push,omitempty"`
+	PullRequest      *PullRequestTrigger      `yaml:"pull_request,omitempty"`
+	WorkflowDispatch *WorkflowDispatchTrigger `yaml:"workflow_dispatch,omitempty"`
+	Schedule         []ScheduleTrigger        `yaml:"schedule,omitempty"`
+}
+
+type PushTrigger struct {
+	Branches []string `yaml:"branches,omitempty"`
+	Tags     []string `yaml:"tags,omitempty"`
+	Paths    []string `yaml:"paths,omitempty"`
+}
+
+type PullRequestTrigger struct {
+	// Branches are the patterns of the branches that the pull request will be merged into. If empty, every pull request triggers the workflow.
+	Branches []string `yaml:"branches,omitempty"`
+	Paths    []string `yaml:"paths,omitempty"`
+}
+
+// WorkflowDispatchTrigger allows the workflow to be started manually from the GitHub UI or API.
+type WorkflowDispatchTrigger struct{}
+
+type ScheduleTrigger struct {
+	Cron string `yaml:"cron"`
+}
+
+// Job is a single job in the workflow. Every Scribe pipeline is converted into a Job.
+type Job struct {
+	Name   string            `yaml:"name"`
+	RunsOn string            `yaml:"runs-on"`
+	Needs  []string          `yaml:"needs,omitempty"`
+	If     string            `yaml:"if,omitempty"`
+	Env    map[string]string `yaml:"env,omitempty"`
+	// TimeoutMinutes is the number of minutes that the job can run before GitHub cancels it. If it is 0, then GitHub's default is used.
+	TimeoutMinutes int    `yaml:"timeout-minutes,omitempty"`
+	Steps          []Step `yaml:"steps"`
+}
+
+// Step is a single step in a Job.
+type Step struct {
+	Name string            `yaml:"name,omitempty"`
+	Uses string            `yaml:"uses,omitempty"`
+	With map[string]string `yaml:"with,omitempty"`
+	Env  map[string]string `yaml:"env,omitempty"`
+	Run  string            `yaml:"run,omitempty"`
+}
+
+func secretName(key string) string {
+	return strings.ToUpper(stringutil.Slugify(key))
+}
+
+func secretEnv(key string) string {
+	return fmt.Sprintf("SECRET_%s", secretName(key))
+}
+
+// HandleSecrets handles the different 'Secret' arguments that are required by the steps in the pipeline.
+// Secrets are placed in the job's environment from the repository's secrets (`${{ secrets.X }}`), and that environment variable is then provided to the pipeline using the `-arg` flag.
+// Arguments that events provide are passed using the `-arg` flag from their GitHub equivalents (see eventArgEnvMap).
+func HandleSecrets(p pipeline.Pipeline) (map[string]string, map[string]string) {
+	var (
+		env  = make(map[string]string)
+		args = make(map[string]string)
+	)
+
+	for _, node := range p.Graph.Nodes {
+		for _, step := range node.Value.Steps {
+			for _, arg := range step.Arguments {
+				if val, ok := eventArgEnvMap[arg]; ok {
+					args[arg.Key] = fmt.Sprintf(`"%s"`, val)
+					continue
+				}
+
+				if arg.Type != state.ArgumentTypeSecret {
+					continue
+				}
+
+				name := secretEnv(arg.Key)
+				env[name] = fmt.Sprintf("${{ secrets.%s }}", secretName(arg.Key))
+				args[arg.Key] = "$" + name
+			}
+		}
+	}
+
+	return env, args
+}
+
+// NewRunStep creates the workflow step that runs an entire Scribe pipeline using the compiled pipeline and the CLI client.
+func NewRunStep(path, state, version string, p pipeline.Pipeline) (Step, error) {
+	env, argMap := HandleSecrets(p)
+
+	cmd, err := cmdutil.PipelineCommand(cmdutil.PipelineCommandOpts{
+		Pipeline: p,
+		CommandOpts: cmdutil.CommandOpts{
+			CompiledPipeline: PipelinePath,
+			PipelineArgs: args.PipelineArgs{
+				Path:     path,
+				BuildID:  "$GITHUB_RUN_ID",
+				State:    state,
+				ArgMap:   argMap,
+				Client:   "cli",
+				LogLevel: logrus.DebugLevel,
+				Version:  version,
+			},
+		},
+	})
+
+	if err != nil {
+		return Step{}, err
+	}
+
+	return Step{
+		Name: stringutil.Slugify(p.Name),
+		Env:  env,
+		Run:  strings.Join(cmd, " "),
+	}, nil
+}
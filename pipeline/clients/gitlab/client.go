@@ -0,0 +1,202 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/scribe/errors"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/grafana/scribe/pipelineutil"
+	"github.com/grafana/scribe/stringutil"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	ErrorNoImage = errors.NewPipelineError("no image provided", "An image is required for background steps without an action in GitLab CI because they are converted into services. You can specify one with the '.WithImage(\"name\")' function.")
+	ErrorNoName  = errors.NewPipelineError("no name provided", "A name is required for all steps in GitLab CI. You can specify one with the '.WithName(\"name\")' function.")
+)
+
+var (
+	// PipelinePath is where the pipeline is compiled to. It is relative to the project directory because it is passed to the other jobs as an artifact.
+	PipelinePath = ".scribe/pipeline"
+	StatePath    = "/var/scribe-state"
+	Image        = "golang:1.19"
+	CompileJob   = "builtin-compile-pipeline"
+	CompileStage = "compile"
+)
+
+// Client is the GitLab CI implementation of the pipeline Client interface.
+// It will create a `.gitlab-ci.yml` file that will run your pipeline the same way that it would run locally.
+// The pipeline is compiled once in the first stage and every Scribe pipeline is converted into a job that runs the compiled pipeline with the CLI client.
+type Client struct {
+	Opts clients.CommonOpts
+
+	Log *logrus.Logger
+}
+
+// Validate ensures that your step has a name, and that background steps without an action have a docker image.
+func (c *Client) Validate(step pipeline.Step) error {
+	if step.Name == "" {
+		return ErrorNoName
+	}
+
+	if step.IsBackground() && step.Action == nil && step.Image == "" {
+		return ErrorNoImage
+	}
+
+	if len(step.Events) != 0 {
+		return clients.StepEventsError("Use a separate pipeline with '.When(...)' instead.")
+	}
+
+	return nil
+}
+
+func stageName(i int) string {
+	return fmt.Sprintf("stage-%d", i)
+}
+
+// stages returns the stage of every pipeline by name. The stage of a pipeline is one greater than the greatest stage of its dependencies.
+func stages(pipelines []pipeline.Pipeline) map[string]int {
+	byName := map[string]pipeline.Pipeline{}
+	for _, v := range pipelines {
+		byName[v.Name] = v
+	}
+
+	s := map[string]int{}
+	var stage func(p pipeline.Pipeline) int
+	stage = func(p pipeline.Pipeline) int {
+		if v, ok := s[p.Name]; ok {
+			return v
+		}
+
+		n := 1
+		for _, v := range p.Dependencies {
+			if d, ok := byName[v.Name]; ok {
+				v = d
+			}
+			if i := stage(v) + 1; i > n {
+				n = i
+			}
+		}
+
+		s[p.Name] = n
+		return n
+	}
+
+	for _, v := range pipelines {
+		stage(v)
+	}
+
+	return s
+}
+
+func (c *Client) compileJob() *Job {
+	command := pipelineutil.GoBuild(context.Background(), pipelineutil.GoBuildOpts{
+		Pipeline: c.Opts.Args.Path,
+		Output:   PipelinePath,
+	})
+
+	return &Job{
+		Stage: CompileStage,
+		Image: Image,
+		Variables: map[string]string{
+			"CGO_ENABLED": "0",
+			"GOOS":        "linux",
+			"GOARCH":      "amd64",
+		},
+		Script: []string{strings.Join(command.Args, " ")},
+		Artifacts: &Artifacts{
+			Paths: []string{PipelinePath},
+		},
+	}
+}
+
+func (c *Client) newJob(p pipeline.Pipeline, stage int) (*Job, error) {
+	script, err := PipelineScript(c.Opts.Args.Path, "file://"+StatePath+"/state.json", c.Opts.Version, p)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := Events(p.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pipelines that this one depends on are optional because they may not be in the GitLab pipeline if their rules did not match.
+	needs := []Need{{Job: CompileJob, Artifacts: true}}
+	for _, v := range p.Dependencies {
+		needs = append(needs, Need{
+			Job:      stringutil.Slugify(v.Name),
+			Optional: true,
+		})
+	}
+
+	return &Job{
+		Stage:    stageName(stage),
+		Image:    Image,
+		Needs:    needs,
+		Rules:    rules,
+		Services: Services(p),
+		Script:   []string{script},
+		// Each step's timeout is enforced by the pipeline; the job's timeout is only set when every step has one.
+		Timeout: JobTimeout(p),
+	}, nil
+}
+
+// Done traverses through the tree and writes a .gitlab-ci.yml file to the provided writer.
+func (c *Client) Done(ctx context.Context, w pipeline.Walker) error {
+	var (
+		log       = c.Log.WithField("client", "gitlab")
+		pipelines = []pipeline.Pipeline{}
+	)
+
+	if err := clients.WarnArguments(ctx, w, c.Opts, log, knownArgument); err != nil {
+		return err
+	}
+
+	err := w.WalkPipelines(ctx, func(ctx context.Context, p ...pipeline.Pipeline) error {
+		log.Debugf("Walking '%d' pipelines...", len(p))
+		pipelines = append(pipelines, p...)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	var (
+		s        = stages(pipelines)
+		maxStage = 0
+		jobs     = yaml.MapSlice{{Key: CompileJob, Value: c.compileJob()}}
+	)
+
+	for _, v := range pipelines {
+		log.Debugf("Processing pipeline '%s'...", v.Name)
+		job, err := c.newJob(v, s[v.Name])
+		if err != nil {
+			return err
+		}
+
+		if s[v.Name] > maxStage {
+			maxStage = s[v.Name]
+		}
+
+		jobs = append(jobs, yaml.MapItem{
+			Key:   stringutil.Slugify(v.Name),
+			Value: job,
+		})
+		log.Debugf("Done processing pipeline '%s'", v.Name)
+	}
+
+	stageNames := []string{CompileStage}
+	for i := 1; i <= maxStage; i++ {
+		stageNames = append(stageNames, stageName(i))
+	}
+
+	config := append(yaml.MapSlice{{Key: "stages", Value: stageNames}}, jobs...)
+
+	return yaml.NewEncoder(c.Opts.Output).Encode(config)
+}
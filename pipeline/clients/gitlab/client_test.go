@@ -0,0 +1,68 @@
+package gitlab_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+// testDemoPipeline tests a pipeline located in "demo" folder. the "path" argument should be relative to the demo folder in the root of the project.
+// This function will do a basic equivalency check on what is generated by running the pipeline with the gitlab client and what is in the "gen_gitlab.yml" file in the provided folder.
+func testDemoPipeline(t *testing.T, path string) {
+	t.Helper()
+
+	var (
+		buf          = bytes.NewBuffer(nil)
+		stderr       = bytes.NewBuffer(nil)
+		ctx          = context.Background()
+		pipelinePath = filepath.Join("../../../demo", path)
+	)
+
+	testutil.RunPipeline(ctx, t, pipelinePath, io.MultiWriter(buf, os.Stdout), stderr, &args.PipelineArgs{
+		BuildID:  "test",
+		Client:   "gitlab",
+		Path:     fmt.Sprintf("./demo/%s", path), // Note that we're intentionally using ./demo/ instead of filepath because this path is used in a Go command.
+		LogLevel: logrus.DebugLevel,
+	})
+
+	t.Log(stderr.String())
+
+	expected, err := os.Open(filepath.Join(pipelinePath, "gen_gitlab.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.ReadersEqual(t, buf, expected)
+}
+
+func TestGitLabClient(t *testing.T) {
+	t.Run("It should generate a simple GitLab CI config",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "basic")
+		}),
+	)
+	t.Run("It should generate a config with multiple stages and rules",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "multi")
+		}),
+	)
+	t.Run("It should generate a config with a sub-pipeline",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "sub")
+		}),
+	)
+	t.Run("It should only convert background steps without an action into services",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "complex")
+		}),
+	)
+}
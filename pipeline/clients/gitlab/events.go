@@ -0,0 +1,125 @@
+package gitlab
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/scribe/errors"
+	"github.com/grafana/scribe/pipeline"
+)
+
+var (
+	ErrorUnsupportedEvent = errors.NewPipelineError("unsupported event", "The GitLab client only supports git commit, git tag, pull request, cron, and manual events.")
+	ErrorPathsFilter      = errors.NewPipelineError("unsupported paths filter", "GitLab 'rules:changes' only supports glob patterns that include files. Use 'pipeline.PathsChanged' without 'pipeline.Not'.")
+)
+
+// globToRegex converts a glob pattern into an equivalent regular expression that matches the entire value.
+func globToRegex(glob string) string {
+	b := strings.Builder{}
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+
+	return b.String()
+}
+
+// condition returns a CI/CD variable expression that matches the variable against the filter value.
+// If there is no filter, then the expression is true whenever the variable is set.
+func condition(variable string, f *pipeline.FilterValue) string {
+	if f == nil {
+		return variable
+	}
+
+	// Excluding filters use the negated operators. The variable must still be set, so that the rule does not match other events.
+	var (
+		equals  = "=="
+		matches = "=~"
+		prefix  = ""
+	)
+
+	if f.Exclude {
+		equals = "!="
+		matches = "!~"
+		prefix = variable + " && "
+	}
+
+	switch f.Type {
+	case pipeline.FilterValueRegex:
+		return fmt.Sprintf("%s%s %s /%s/", prefix, variable, matches, strings.ReplaceAll(f.String(), "/", `\/`))
+	case pipeline.FilterValueGlob:
+		return fmt.Sprintf("%s%s %s /%s/", prefix, variable, matches, strings.ReplaceAll(globToRegex(f.String()), "/", `\/`))
+	}
+
+	return fmt.Sprintf("%s%s %s %q", prefix, variable, equals, f.String())
+}
+
+// changes returns the 'rules:changes' patterns for the event's paths filter, or nil if the event is not filtered by paths.
+func changes(e pipeline.Event) ([]string, error) {
+	f := e.Filters["paths"]
+	if f == nil {
+		return nil, nil
+	}
+
+	if f.Exclude || f.Type == pipeline.FilterValueRegex {
+		return nil, ErrorPathsFilter
+	}
+
+	return []string{f.String()}, nil
+}
+
+func eventRule(e pipeline.Event) (Rule, error) {
+	paths, err := changes(e)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	switch e.Name {
+	case "git-commit":
+		return Rule{If: condition("$CI_COMMIT_BRANCH", e.Filters["branch"]), Changes: paths}, nil
+	case "git-tag":
+		return Rule{If: condition("$CI_COMMIT_TAG", e.Filters["tag"])}, nil
+	case "pull-request":
+		cond := `$CI_PIPELINE_SOURCE == "merge_request_event"`
+		if f := e.Filters["branch"]; f != nil {
+			cond = fmt.Sprintf("%s && %s", cond, condition("$CI_MERGE_REQUEST_TARGET_BRANCH_NAME", f))
+		}
+		return Rule{If: cond, Changes: paths}, nil
+	case "cron":
+		// GitLab schedules are created in the project settings, so only the description of the schedule can be used to filter the event.
+		cond := `$CI_PIPELINE_SOURCE == "schedule"`
+		if f := e.Filters["cron"]; f != nil {
+			cond = fmt.Sprintf("%s && %s", cond, condition("$CI_PIPELINE_SCHEDULE_DESCRIPTION", f))
+		}
+		return Rule{If: cond}, nil
+	case "manual":
+		return Rule{If: `$CI_PIPELINE_SOURCE == "web"`}, nil
+	}
+
+	return Rule{}, fmt.Errorf("%w: '%s'", ErrorUnsupportedEvent, e.Name)
+}
+
+// Events converts the list of pipeline.Events to a list of GitLab 'rules'.
+// A job is added to the pipeline if any of its rules match. If there are no events, then there are no rules and the job is always added.
+func Events(events []pipeline.Event) ([]Rule, error) {
+	rules := make([]Rule, len(events))
+	for i, event := range events {
+		r, err := eventRule(event)
+		if err != nil {
+			return nil, err
+		}
+
+		rules[i] = r
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,113 @@
+package gitlab_test
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/grafana/scribe"
+	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/grafana/scribe/pipeline/clients/gitlab"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestEvents(t *testing.T) {
+	rules, err := gitlab.Events([]pipeline.Event{
+		pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+			Branch: pipeline.StringFilter("main"),
+		}),
+		pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+			Branch: pipeline.RegexpFilter(regexp.MustCompile("^release/.*$")),
+		}),
+		pipeline.GitTagEvent(pipeline.GitTagFilters{
+			Name: pipeline.GlobFilter("v1.?.*"),
+		}),
+		pipeline.PullRequestEvent(pipeline.PullRequestFilters{}),
+		pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+			Branch: pipeline.Not(pipeline.StringFilter("main")),
+		}),
+		pipeline.PullRequestEvent(pipeline.PullRequestFilters{
+			Branch: pipeline.StringFilter("main"),
+		}),
+		pipeline.CronEvent(pipeline.CronFilters{
+			Name: pipeline.StringFilter("nightly"),
+		}),
+		pipeline.ManualEvent(),
+	})
+	testutil.EnsureError(t, err, nil)
+
+	expected := []string{
+		`$CI_COMMIT_BRANCH == "main"`,
+		`$CI_COMMIT_BRANCH =~ /^release\/.*$/`,
+		`$CI_COMMIT_TAG =~ /^v1\..\..*$/`,
+		`$CI_PIPELINE_SOURCE == "merge_request_event"`,
+		`$CI_COMMIT_BRANCH && $CI_COMMIT_BRANCH != "main"`,
+		`$CI_PIPELINE_SOURCE == "merge_request_event" && $CI_MERGE_REQUEST_TARGET_BRANCH_NAME == "main"`,
+		`$CI_PIPELINE_SOURCE == "schedule" && $CI_PIPELINE_SCHEDULE_DESCRIPTION == "nightly"`,
+		`$CI_PIPELINE_SOURCE == "web"`,
+	}
+
+	if len(rules) != len(expected) {
+		t.Fatalf("Expected '%d' rules but got '%d'", len(expected), len(rules))
+	}
+
+	for i, v := range expected {
+		if rules[i].If != v {
+			t.Errorf("Expected rule '%d' to be '%s' but got '%s'", i, v, rules[i].If)
+		}
+	}
+}
+
+func TestEventsPaths(t *testing.T) {
+	rules, err := gitlab.Events([]pipeline.Event{
+		pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+			Paths: pipeline.PathsChanged("docs/**"),
+		}),
+	})
+	testutil.EnsureError(t, err, nil)
+
+	if len(rules) != 1 || len(rules[0].Changes) != 1 || rules[0].Changes[0] != "docs/**" {
+		t.Fatalf("Expected a rule with the changes 'docs/**' but got '%+v'", rules)
+	}
+
+	_, err = gitlab.Events([]pipeline.Event{
+		pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+			Paths: pipeline.Not(pipeline.PathsChanged("docs/**")),
+		}),
+	})
+	testutil.EnsureError(t, err, gitlab.ErrorPathsFilter)
+}
+
+func TestServices(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	opts := clients.CommonOpts{
+		Name:   "test",
+		Output: buf,
+		Log:    logrus.New(),
+		Args: &args.PipelineArgs{
+			Path: "./ci",
+		},
+	}
+
+	col := scribe.NewDefaultCollection(opts)
+	testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, pipeline.StepList{
+		ID:   2,
+		Type: pipeline.StepTypeBackground,
+		Steps: []pipeline.Step{
+			{Name: "redis", Image: "redis:6", Type: pipeline.StepTypeBackground},
+			{Name: "Postgres_DB 14.1", Image: "postgres:14", Type: pipeline.StepTypeBackground},
+		},
+	}), nil)
+
+	testutil.EnsureError(t, gitlab.New(opts).Done(context.Background(), col), nil)
+
+	expected := "services:\n  - name: redis:6\n    alias: redis\n  - name: postgres:14\n    alias: postgres-db-14-1\n"
+	if !strings.Contains(buf.String(), expected) {
+		t.Fatalf("Expected output to contain\n%s\nbut got\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,157 @@
+package gitlab
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/cmdutil"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/grafana/scribe/pipeline/clients/cli"
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/stringutil"
+	"github.com/sirupsen/logrus"
+)
+
+// Job is a single job in the GitLab CI config. Every Scribe pipeline is converted into a Job.
+// See https://docs.gitlab.com/ee/ci/yaml/.
+type Job struct {
+	Stage     string            `yaml:"stage"`
+	Image     string            `yaml:"image"`
+	Needs     []Need            `yaml:"needs,omitempty"`
+	Rules     []Rule            `yaml:"rules,omitempty"`
+	Services  []Service         `yaml:"services,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Script    []string          `yaml:"script"`
+	Artifacts *Artifacts        `yaml:"artifacts,omitempty"`
+	// Timeout is how long the job can run before GitLab cancels it, like '1h 30m'. If it is empty, then the project's default is used.
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// Need is a job that must complete before another job starts.
+// If 'Optional' is true, then the job still runs if the needed job is not in the pipeline because of its rules.
+type Need struct {
+	Job       string `yaml:"job"`
+	Artifacts bool   `yaml:"artifacts"`
+	Optional  bool   `yaml:"optional,omitempty"`
+}
+
+// Rule determines whether a job is added to the pipeline.
+type Rule struct {
+	If string `yaml:"if"`
+	// Changes are the glob patterns of files. If set, the rule only matches if one of the files was changed.
+	Changes []string `yaml:"changes,omitempty"`
+}
+
+// Service is a container that runs alongside a job for its entire duration.
+type Service struct {
+	Name  string `yaml:"name"`
+	Alias string `yaml:"alias"`
+}
+
+type Artifacts struct {
+	Paths []string `yaml:"paths"`
+}
+
+// secretVariable returns the name of the CI/CD variable that holds the value of a secret argument.
+func secretVariable(key string) string {
+	return strings.ToUpper(stringutil.Slugify(key))
+}
+
+// knownArgument returns true if the value of the argument is available in GitLab CI before any step runs.
+// Secrets are read from CI/CD variables, and generated pipelines are ran with the CLI client, so the arguments that it adds to the state are also known.
+func knownArgument(arg state.Argument) bool {
+	return arg.Type == state.ArgumentTypeSecret || cli.KnownArgument(arg)
+}
+
+// HandleSecrets handles the different 'Secret' arguments that are required by the steps in the pipeline.
+// Secrets are not defined in the config. They are expected to be defined as masked CI/CD variables in the project, and those variables are provided to the pipeline using the `-arg` flag.
+func HandleSecrets(p pipeline.Pipeline) map[string]string {
+	args := make(map[string]string)
+
+	for _, node := range p.Graph.Nodes {
+		for _, step := range node.Value.Steps {
+			for _, arg := range step.Arguments {
+				if arg.Type != state.ArgumentTypeSecret {
+					continue
+				}
+
+				args[arg.Key] = "$" + secretVariable(arg.Key)
+			}
+		}
+	}
+
+	return args
+}
+
+var invalidAliasChars = regexp.MustCompile("[^a-z0-9]+")
+
+// serviceAlias returns a name for the service that can be used as a hostname, as GitLab makes services available to the job by their alias.
+// The name is lowercased, every sequence of characters other than letters and numbers is replaced with a '-', and it is limited to 63 characters.
+func serviceAlias(name string) string {
+	alias := invalidAliasChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(alias) > 63 {
+		alias = alias[:63]
+	}
+
+	return strings.Trim(alias, "-")
+}
+
+// Services returns a GitLab service for every background step in the pipeline that has no action.
+// Background steps with an action are ran by the Scribe CLI with the rest of the pipeline instead.
+func Services(p pipeline.Pipeline) []Service {
+	services := []Service{}
+
+	for _, node := range p.Graph.Nodes {
+		for _, step := range node.Value.Steps {
+			if !step.IsBackground() || step.Action != nil {
+				continue
+			}
+
+			services = append(services, Service{
+				Name:  step.Image,
+				Alias: serviceAlias(step.Name),
+			})
+		}
+	}
+
+	return services
+}
+
+// JobTimeout returns the timeout of the job that runs the pipeline in minutes, like '45m', or an empty string if a step in the pipeline has no timeout.
+func JobTimeout(p pipeline.Pipeline) string {
+	d := clients.JobTimeout(p)
+	if d == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%dm", int(math.Ceil(d.Minutes())))
+}
+
+// PipelineScript returns the command that runs an entire Scribe pipeline using the compiled pipeline and the CLI client.
+func PipelineScript(path, state, version string, p pipeline.Pipeline) (string, error) {
+	cmd, err := cmdutil.PipelineCommand(cmdutil.PipelineCommandOpts{
+		Pipeline: p,
+		CommandOpts: cmdutil.CommandOpts{
+			CompiledPipeline: PipelinePath,
+			PipelineArgs: args.PipelineArgs{
+				Path:     path,
+				BuildID:  "$CI_PIPELINE_ID",
+				State:    state,
+				ArgMap:   HandleSecrets(p),
+				Client:   "cli",
+				LogLevel: logrus.DebugLevel,
+				Version:  version,
+			},
+		},
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(cmd, " "), nil
+}
@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/grafana/scribe/errors"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+	FormatJSON    = "json"
+)
+
+var (
+	ErrorUnknownFormat = errors.NewPipelineError("unknown graph format", "The graph client supports the 'dot', 'mermaid', and 'json' formats. Use the '--graph-format' argument to select one.")
+)
+
+// Client is the graph implementation of the pipeline Client interface.
+// Rather than running the pipeline, it writes the structure of every pipeline in the collection to the output, so that it can be reviewed or added to documentation.
+// The '-graph-format' argument selects whether a Graphviz DOT (the default), Mermaid, or JSON document is written.
+type Client struct {
+	Opts clients.CommonOpts
+
+	Log *logrus.Logger
+}
+
+// Validate does nothing, as every step can be drawn in a graph.
+func (c *Client) Validate(step pipeline.Step) error {
+	return nil
+}
+
+func (c *Client) Done(ctx context.Context, w pipeline.Walker) error {
+	format := c.Opts.Args.GraphFormat
+	if format == "" {
+		format = FormatDOT
+	}
+
+	g, err := NewGraph(ctx, w)
+	if err != nil {
+		return err
+	}
+
+	// Multi-pipelines do not have a name, so the name of the folder that contains the pipeline is used instead.
+	name := c.Opts.Name
+	if name == "" {
+		name = filepath.Base(c.Opts.Args.Path)
+	}
+
+	switch format {
+	case FormatDOT:
+		return WriteDOT(c.Opts.Output, name, g)
+	case FormatMermaid:
+		return WriteMermaid(c.Opts.Output, g)
+	case FormatJSON:
+		enc := json.NewEncoder(c.Opts.Output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g)
+	}
+
+	return fmt.Errorf("%w: '%s'", ErrorUnknownFormat, format)
+}
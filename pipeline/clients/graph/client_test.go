@@ -0,0 +1,119 @@
+package graph_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/scribe"
+	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/grafana/scribe/pipeline/clients/graph"
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+// testDemoPipeline tests a pipeline located in "demo" folder. the "path" argument should be relative to the demo folder in the root of the project.
+// This function will do a basic equivalency check on what is generated by running the pipeline with the graph client and what is in the "file" in the provided folder.
+func testDemoPipeline(t *testing.T, path, format, file string) {
+	t.Helper()
+
+	var (
+		buf          = bytes.NewBuffer(nil)
+		stderr       = bytes.NewBuffer(nil)
+		ctx          = context.Background()
+		pipelinePath = filepath.Join("../../../demo", path)
+	)
+
+	testutil.RunPipeline(ctx, t, pipelinePath, io.MultiWriter(buf, os.Stdout), stderr, &args.PipelineArgs{
+		BuildID:     "test",
+		Client:      "graph",
+		Path:        fmt.Sprintf("./demo/%s", path), // Note that we're intentionally using ./demo/ instead of filepath because this path is used in a Go command.
+		LogLevel:    logrus.DebugLevel,
+		GraphFormat: format,
+	})
+
+	t.Log(stderr.String())
+
+	expected, err := os.Open(filepath.Join(pipelinePath, file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.ReadersEqual(t, buf, expected)
+}
+
+func TestGraphClient(t *testing.T) {
+	t.Run("It should generate a DOT document for a simple pipeline",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "basic", graph.FormatDOT, "gen_graph.dot")
+		}),
+	)
+	t.Run("It should generate a DOT document with sub-pipelines and pipeline dependencies",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "multi-sub", graph.FormatDOT, "gen_graph.dot")
+		}),
+	)
+	t.Run("It should generate a Mermaid flowchart with sub-pipelines and pipeline dependencies",
+		testutil.WithTimeout(time.Second*10, func(t *testing.T) {
+			testDemoPipeline(t, "multi-sub", graph.FormatMermaid, "gen_graph.mmd")
+		}),
+	)
+}
+
+func TestNewGraph(t *testing.T) {
+	var (
+		argA = state.NewStringArgument("a")
+		argB = state.NewStringArgument("b")
+	)
+
+	opts := clients.CommonOpts{
+		Name: "test",
+		Log:  logrus.New(),
+	}
+
+	col := scribe.NewDefaultCollection(opts)
+	testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, pipeline.NewStepList(2,
+		pipeline.Step{ID: 2, Name: "redis", Type: pipeline.StepTypeBackground},
+	)), nil)
+
+	first := pipeline.NewStepList(3, pipeline.Step{ID: 3, Name: "provide", ProvidesArgs: []state.Argument{argA, argB}})
+	testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, first), nil)
+
+	second := pipeline.NewStepList(4, pipeline.Step{ID: 4, Name: "require", Arguments: []state.Argument{argB}})
+	second.Dependencies = []pipeline.StepList{first}
+	testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, second), nil)
+
+	g, err := graph.NewGraph(context.Background(), col)
+	testutil.EnsureError(t, err, nil)
+
+	expected := &graph.Graph{
+		Pipelines: []graph.Pipeline{
+			{
+				ID:     scribe.DefaultPipelineID,
+				Name:   "test",
+				Events: []string{"git-commit"},
+				Nodes: []graph.Node{
+					{ID: 2, Steps: []string{"redis"}, Background: true},
+					{ID: 3, Steps: []string{"provide"}},
+					{ID: 4, Steps: []string{"require"}},
+				},
+				Edges: []graph.Edge{
+					{From: 3, To: 4, Arguments: []string{"b"}},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, g); diff != "" {
+		t.Fatal(diff)
+	}
+}
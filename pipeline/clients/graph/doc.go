@@ -0,0 +1,2 @@
+// Package graph contains the graph client implementation, which exports the structure of a pipeline as a Graphviz DOT, Mermaid, or JSON document.
+package graph
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func dotEscape(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return r.Replace(v)
+}
+
+// WriteDOT writes the graph as a Graphviz DOT document.
+// Every pipeline is a cluster and every list of steps is a node. Background steps and sub-pipelines are drawn with dashed lines.
+// Every cluster has an invisible anchor node that is used for the edges between pipelines.
+func WriteDOT(w io.Writer, name string, g *Graph) error {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "digraph \"%s\" {\n", dotEscape(name))
+	b.WriteString("  compound=true;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, p := range g.Pipelines {
+		label := p.Name
+		if p.Sub {
+			label += " (sub-pipeline)"
+		}
+
+		fmt.Fprintf(b, "\n  subgraph cluster_%d {\n", p.ID)
+		fmt.Fprintf(b, "    label=\"%s\";\n", dotEscape(label))
+		if p.Sub {
+			b.WriteString("    style=dashed;\n")
+		}
+		fmt.Fprintf(b, "    p%d [shape=point, style=invis];\n", p.ID)
+
+		for _, n := range p.Nodes {
+			steps := make([]string, len(n.Steps))
+			for i, v := range n.Steps {
+				steps[i] = dotEscape(v)
+			}
+
+			attrs := fmt.Sprintf("label=\"%s\"", strings.Join(steps, `\n`))
+			if n.Background {
+				attrs += ", shape=ellipse, style=dashed"
+			}
+
+			fmt.Fprintf(b, "    %s [%s];\n", nodeID(p, n.ID), attrs)
+		}
+
+		for _, e := range p.Edges {
+			if len(e.Arguments) == 0 {
+				fmt.Fprintf(b, "    %s -> %s;\n", nodeID(p, e.From), nodeID(p, e.To))
+				continue
+			}
+
+			fmt.Fprintf(b, "    %s -> %s [label=\"%s\"];\n", nodeID(p, e.From), nodeID(p, e.To), dotEscape(strings.Join(e.Arguments, ", ")))
+		}
+
+		b.WriteString("  }\n")
+	}
+
+	edges := false
+	for _, p := range g.Pipelines {
+		for _, dep := range p.Dependencies {
+			if !edges {
+				b.WriteString("\n")
+				edges = true
+			}
+			fmt.Fprintf(b, "  p%d -> p%d [ltail=cluster_%d, lhead=cluster_%d];\n", dep, p.ID, dep, p.ID)
+		}
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
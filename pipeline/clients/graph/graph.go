@@ -0,0 +1,187 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/scribe/pipeline"
+)
+
+// Graph is the structure of every pipeline in a collection.
+type Graph struct {
+	Pipelines []Pipeline `json:"pipelines"`
+}
+
+// Pipeline is a single pipeline in the Graph. Every list of steps that runs in parallel is a Node in the pipeline.
+type Pipeline struct {
+	ID           int64    `json:"id"`
+	Name         string   `json:"name"`
+	Sub          bool     `json:"sub,omitempty"`
+	Events       []string `json:"events,omitempty"`
+	Dependencies []int64  `json:"dependencies,omitempty"`
+	Nodes        []Node   `json:"nodes"`
+	Edges        []Edge   `json:"edges"`
+}
+
+// Node is a list of steps that run in parallel. Its ID is the ID of the first step in the list.
+type Node struct {
+	ID         int64    `json:"id"`
+	Steps      []string `json:"steps"`
+	Background bool     `json:"background,omitempty"`
+}
+
+// Edge is a dependency between two nodes in the same pipeline.
+// The Arguments are the keys of the arguments that the steps in 'To' require and the steps in 'From' provide.
+type Edge struct {
+	From      int64    `json:"from"`
+	To        int64    `json:"to"`
+	Arguments []string `json:"arguments,omitempty"`
+}
+
+// edgeArguments returns the keys of the arguments that are provided by the steps in 'from' and required by the steps in 'to'.
+func edgeArguments(from, to []pipeline.Step) []string {
+	provides := map[string]bool{}
+	for _, step := range from {
+		for _, arg := range step.ProvidesArgs {
+			provides[arg.Key] = true
+		}
+	}
+
+	args := []string{}
+	for _, step := range to {
+		for _, arg := range step.Arguments {
+			if provides[arg.Key] {
+				args = appendUnique(args, arg.Key)
+			}
+		}
+	}
+
+	sort.Strings(args)
+	return args
+}
+
+func appendUnique(s []string, v string) []string {
+	for _, value := range s {
+		if value == v {
+			return s
+		}
+	}
+
+	return append(s, v)
+}
+
+// newPipeline walks the steps of the pipeline and converts them into nodes and edges.
+// The edges are added after every node is walked because a list of steps may be walked before the lists it depends on.
+func newPipeline(ctx context.Context, w pipeline.Walker, p pipeline.Pipeline) (Pipeline, error) {
+	var (
+		lists = [][]pipeline.Step{}
+		// nodes maps every step ID to the ID of the node that contains it.
+		nodes = map[int64]int64{}
+	)
+
+	if err := w.WalkSteps(ctx, p.ID, func(ctx context.Context, steps ...pipeline.Step) error {
+		if len(steps) == 0 {
+			return nil
+		}
+
+		for _, step := range steps {
+			nodes[step.ID] = steps[0].ID
+		}
+
+		lists = append(lists, steps)
+		return nil
+	}); err != nil {
+		return Pipeline{}, err
+	}
+
+	v := Pipeline{
+		ID:     p.ID,
+		Name:   p.Name,
+		Sub:    p.Type == pipeline.PipelineTypeSub,
+		Events: make([]string, len(p.Events)),
+		Nodes:  make([]Node, len(lists)),
+		Edges:  []Edge{},
+	}
+
+	for i, e := range p.Events {
+		v.Events[i] = e.Name
+	}
+
+	for _, dep := range p.Dependencies {
+		v.Dependencies = append(v.Dependencies, dep.ID)
+	}
+
+	steps := map[int64][]pipeline.Step{}
+	for i, list := range lists {
+		id := list[0].ID
+		steps[id] = list
+		v.Nodes[i] = Node{
+			ID:         id,
+			Steps:      pipeline.StepNames(list),
+			Background: list[0].IsBackground(),
+		}
+	}
+
+	for _, list := range lists {
+		from := []int64{}
+		for _, dep := range list[0].Dependencies {
+			id, ok := nodes[dep.ID]
+			if !ok {
+				return Pipeline{}, fmt.Errorf("step '%s' depends on step '%s' which is not in pipeline '%s'", list[0].Name, dep.Name, p.Name)
+			}
+
+			if !containsID(from, id) {
+				from = append(from, id)
+			}
+		}
+
+		for _, id := range from {
+			v.Edges = append(v.Edges, Edge{
+				From:      id,
+				To:        list[0].ID,
+				Arguments: edgeArguments(steps[id], list),
+			})
+		}
+	}
+
+	return v, nil
+}
+
+// nodeID returns an identifier for the node that is unique in the Graph, because the IDs of steps are only unique in their pipeline.
+func nodeID(p Pipeline, id int64) string {
+	return fmt.Sprintf("p%d_s%d", p.ID, id)
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewGraph walks every pipeline in the collection and returns its Graph.
+func NewGraph(ctx context.Context, w pipeline.Walker) (*Graph, error) {
+	g := &Graph{
+		Pipelines: []Pipeline{},
+	}
+
+	if err := w.WalkPipelines(ctx, func(ctx context.Context, pipelines ...pipeline.Pipeline) error {
+		for _, p := range pipelines {
+			v, err := newPipeline(ctx, w, p)
+			if err != nil {
+				return err
+			}
+
+			g.Pipelines = append(g.Pipelines, v)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// mermaidEscape escapes the characters that can not be used in a quoted Mermaid label.
+func mermaidEscape(v string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+	return r.Replace(v)
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart, which can be embedded in Markdown documents and pull request descriptions.
+// Every pipeline is a subgraph and every list of steps is a node. Background steps and sub-pipelines are drawn with dashed lines.
+func WriteMermaid(w io.Writer, g *Graph) error {
+	b := &strings.Builder{}
+
+	b.WriteString("flowchart TD\n")
+
+	for _, p := range g.Pipelines {
+		label := p.Name
+		if p.Sub {
+			label += " (sub-pipeline)"
+		}
+
+		fmt.Fprintf(b, "  subgraph p%d[\"%s\"]\n", p.ID, mermaidEscape(label))
+		for _, n := range p.Nodes {
+			steps := make([]string, len(n.Steps))
+			for i, v := range n.Steps {
+				steps[i] = mermaidEscape(v)
+			}
+
+			if n.Background {
+				fmt.Fprintf(b, "    %s([\"%s\"]):::background\n", nodeID(p, n.ID), strings.Join(steps, "<br/>"))
+				continue
+			}
+
+			fmt.Fprintf(b, "    %s[\"%s\"]\n", nodeID(p, n.ID), strings.Join(steps, "<br/>"))
+		}
+
+		for _, e := range p.Edges {
+			if len(e.Arguments) == 0 {
+				fmt.Fprintf(b, "    %s --> %s\n", nodeID(p, e.From), nodeID(p, e.To))
+				continue
+			}
+
+			fmt.Fprintf(b, "    %s -->|\"%s\"| %s\n", nodeID(p, e.From), mermaidEscape(strings.Join(e.Arguments, ", ")), nodeID(p, e.To))
+		}
+		b.WriteString("  end\n")
+	}
+
+	for _, p := range g.Pipelines {
+		for _, dep := range p.Dependencies {
+			fmt.Fprintf(b, "  p%d --> p%d\n", dep, p.ID)
+		}
+	}
+
+	for _, p := range g.Pipelines {
+		if p.Sub {
+			fmt.Fprintf(b, "  style p%d stroke-dasharray: 5 5\n", p.ID)
+		}
+	}
+
+	b.WriteString("  classDef background stroke-dasharray: 5 5\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
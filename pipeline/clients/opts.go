@@ -4,7 +4,9 @@ import (
 	"io"
 
 	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/cache"
 	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/syncutil"
 	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
 )
@@ -19,4 +21,24 @@ type CommonOpts struct {
 	Log     *logrus.Logger
 	Tracer  opentracing.Tracer
 	State   *state.State
+	Cache   cache.Store
+}
+
+// FailurePolicy returns how clients that run steps should handle a step or pipeline that fails while others run in parallel with it, based on the '-continue-on-error' argument.
+func (c CommonOpts) FailurePolicy() syncutil.FailurePolicy {
+	if c.Args != nil && c.Args.ContinueOnError {
+		return syncutil.ContinueAll
+	}
+
+	return syncutil.FailFast
+}
+
+// Scheduler returns a Scheduler that limits the steps that run at the same time using the '-parallelism' argument.
+// Clients that run steps on the same machine should create one Scheduler and use it for every step.
+func (c CommonOpts) Scheduler() *syncutil.Scheduler {
+	if c.Args == nil {
+		return nil
+	}
+
+	return syncutil.NewScheduler(c.Args.Parallelism)
 }
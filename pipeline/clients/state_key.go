@@ -0,0 +1,19 @@
+package clients
+
+import (
+	"os"
+	"strings"
+)
+
+// StateKeyEnv is the environment variable that holds the key used to encrypt secrets in the state.
+const StateKeyEnv = "SCRIBE_STATE_KEY"
+
+// StateKeyProvided returns true if a key to encrypt secrets in the state was provided with the '-state-key-file' argument or the StateKeyEnv environment variable.
+// Generated configs never include the path to the file, as it is not on the CI runner. Instead, they read StateKeyEnv from a CI secret with the same name.
+func StateKeyProvided(opts CommonOpts) bool {
+	if opts.Args != nil && opts.Args.StateKeyFile != "" {
+		return true
+	}
+
+	return strings.TrimSpace(os.Getenv(StateKeyEnv)) != ""
+}
@@ -0,0 +1,23 @@
+package clients
+
+import (
+	"time"
+
+	"github.com/grafana/scribe/pipeline"
+)
+
+// JobSetupTimeout is added to the longest time that the steps in a pipeline can take to get the timeout of a generated job.
+// It allows time for the job to check out the source and compile the pipeline before the steps run.
+var JobSetupTimeout = 10 * time.Minute
+
+// JobTimeout returns the timeout of a job that runs every step in the pipeline, for clients that generate a single job per pipeline.
+// Each step's timeout and retries are still enforced by the Scribe CLI in the job; the job timeout only stops jobs that the CLI could not.
+// If a step in the pipeline has no timeout, then 0 is returned and the job should use the platform's default timeout.
+func JobTimeout(p pipeline.Pipeline) time.Duration {
+	d := p.MaxDuration()
+	if d == 0 {
+		return 0
+	}
+
+	return d + JobSetupTimeout
+}
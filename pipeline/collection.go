@@ -2,10 +2,10 @@ package pipeline
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/grafana/scribe/errors"
 	"github.com/grafana/scribe/pipeline/dag"
 )
 
@@ -158,11 +158,47 @@ func (c *Collection) WalkSteps(ctx context.Context, pipelineID int64, wf StepWal
 
 	pipeline := node.Value
 
-	if err := pipeline.Graph.BreadthFirstSearch(0, c.stepVisitFunc(ctx, wf)); err != nil {
-		return err
+	// The steps are visited in topological order so that every list of steps is visited after the lists of steps that it depends on.
+	nodes, err := pipeline.Graph.TopologicalSort()
+	if err != nil {
+		return fmt.Errorf("could not sort steps in pipeline '%s'. %w", pipeline.Name, err)
 	}
 
-	return nil
+	// If a list of steps fails, then the rest are still visited with a context that records the failure, so that steps that run on failure (see Step.OnFailure and Step.Always) can run.
+	// Lists without any of those steps are not visited after a failure. The errors of every list that failed are returned in an *errors.ErrorStack.
+	failures := &errors.ErrorStack{}
+	for _, n := range nodes {
+		failure := failures.Err()
+		if failure != nil && !runsOnFailure(n.Value) {
+			continue
+		}
+
+		visit := c.stepVisitFunc(ctx, wf)
+		if failure != nil {
+			visit = c.stepVisitFunc(ContextWithFailure(ctx, failure), wf)
+		}
+
+		if err := visit(n); err != nil {
+			if errors.Is(err, dag.ErrorBreak) {
+				return failure
+			}
+
+			failures.Push(err)
+		}
+	}
+
+	return failures.Err()
+}
+
+// runsOnFailure returns true if any step in the list runs after a step before it failed.
+func runsOnFailure(list StepList) bool {
+	for _, v := range list.Steps {
+		if v.RunsOnFailure() {
+			return true
+		}
+	}
+
+	return false
 }
 
 // AddEvents adds the list of events to the pipeline with 'pipelineID'.
@@ -214,8 +250,139 @@ func (c *Collection) WalkPipelines(ctx context.Context, wf PipelineWalkFunc) err
 	return nil
 }
 
+// artifactProducers returns the StepLists in the graph that produce an artifact that a step in 'steps' uses as an input.
+// StepLists that are already dependencies of 'steps' are not returned.
+// If a step uses an artifact that is produced by a sibling step in the same list, then an error is returned, as they would run at the same time.
+func artifactProducers(graph *dag.Graph[StepList], steps StepList) ([]StepList, error) {
+	inputs := map[string]bool{}
+	for _, step := range steps.Steps {
+		for _, v := range step.Inputs {
+			inputs[v.Name] = true
+		}
+	}
+
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	for _, step := range steps.Steps {
+		for _, v := range step.Outputs {
+			if inputs[v.Name] {
+				return nil, fmt.Errorf("artifact '%s' is produced and used by steps in the same parallel list %s", v.Name, steps.String())
+			}
+		}
+	}
+
+	deps := map[int64]bool{}
+	for _, v := range steps.Dependencies {
+		deps[v.ID] = true
+	}
+
+	producers := []StepList{}
+	for _, node := range graph.Nodes {
+		if node.ID == 0 || deps[node.ID] {
+			continue
+		}
+
+		for _, step := range node.Value.Steps {
+			produces := false
+			for _, v := range step.Outputs {
+				if inputs[v.Name] {
+					produces = true
+					break
+				}
+			}
+
+			if produces {
+				producers = append(producers, node.Value)
+				deps[node.ID] = true
+				break
+			}
+		}
+	}
+
+	return producers, nil
+}
+
+// stepDependencies returns the StepLists in the graph that contain a step that a step in 'steps' runs after with Step.After.
+// StepLists that are already dependencies of 'steps' are not returned.
+// If a step runs after a sibling step in the same list, or after a step that is not in the graph, then an error is returned.
+func stepDependencies(graph *dag.Graph[StepList], steps StepList) ([]StepList, error) {
+	deps := map[int64]bool{}
+	for _, v := range steps.Dependencies {
+		deps[v.ID] = true
+	}
+
+	lists := []StepList{}
+	for _, step := range steps.Steps {
+		for _, dep := range step.Dependencies {
+			for _, sibling := range steps.Steps {
+				if sibling.matchesDependency(dep) {
+					return nil, fmt.Errorf("step '%s' runs after step '%s', but they are in the same parallel list %s", step.Name, dep.Name, steps.String())
+				}
+			}
+
+			found := false
+			for _, node := range graph.Nodes {
+				if node.ID == 0 {
+					continue
+				}
+
+				for _, v := range node.Value.Steps {
+					if v.matchesDependency(dep) {
+						found = true
+						break
+					}
+				}
+
+				if found {
+					if !deps[node.ID] {
+						lists = append(lists, node.Value)
+						deps[node.ID] = true
+					}
+					break
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("%w: step '%s' runs after step '%s', which must be added to the pipeline first", ErrorDependencyNotFound, step.Name, dep.Name)
+			}
+		}
+	}
+
+	return lists, nil
+}
+
+// graphError makes an error from the dag package more readable by replacing the IDs in a cycle with the names of the nodes.
+func graphError[T any](g *dag.Graph[T], err error, name func(T) string) error {
+	cycle := &dag.CycleError{}
+	if !errors.As(err, &cycle) {
+		return err
+	}
+
+	names := make([]string, len(cycle.Path))
+	for i, id := range cycle.Path {
+		names[i] = fmt.Sprint(id)
+		if n, err := g.Node(id); err == nil {
+			names[i] = name(n.Value)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", dag.ErrorCycle, strings.Join(names, " -> "))
+}
+
+func stepListName(s StepList) string {
+	return s.String()
+}
+
+func pipelineName(p Pipeline) string {
+	return fmt.Sprintf("'%s'", p.Name)
+}
+
 // Add adds a new list of Steps which are siblings to a pipeline.
 // Because they are siblings, they must all depend on the same step(s).
+// If any of the steps use an artifact as an input, then the list of steps that produces that artifact is added as a dependency.
+// If any of the steps run after another step with Step.After, then the list of steps that contains that step is also added as a dependency.
 func (c *Collection) AddSteps(pipelineID int64, steps StepList) error {
 	// Find the pipeline in our Graph of pipelines
 	v, err := c.Graph.Node(pipelineID)
@@ -224,14 +391,53 @@ func (c *Collection) AddSteps(pipelineID int64, steps StepList) error {
 	}
 	pipeline := v.Value
 
+	producers, err := artifactProducers(pipeline.Graph, steps)
+	if err != nil {
+		return err
+	}
+
+	if steps.Type != StepTypeBackground {
+		steps.Dependencies = append(steps.Dependencies, producers...)
+
+		dependencies, err := stepDependencies(pipeline.Graph, steps)
+		if err != nil {
+			return err
+		}
+
+		steps.Dependencies = append(steps.Dependencies, dependencies...)
+
+		for _, parent := range steps.Dependencies {
+			if _, err := pipeline.Graph.Node(parent.ID); err != nil {
+				return fmt.Errorf("steps %s depend on steps %s, which are not in the pipeline: %w", steps.String(), parent.String(), err)
+			}
+		}
+	}
+
 	if err := pipeline.AddSteps(steps.ID, steps); err != nil {
 		return fmt.Errorf("error adding steps to pipeline graph: %w", err)
 	}
 
+	if err := addStepEdges(pipeline.Graph, steps); err != nil {
+		// The steps are removed so that the graph is left like it was before they were added.
+		if err := pipeline.Graph.RemoveNode(steps.ID); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("error adding edges to pipeline graph: %w", graphError(pipeline.Graph, err, stepListName))
+	}
+
+	return nil
+}
+
+// addStepEdges adds the edges from the steps' dependencies to the steps.
+// The full graph is not validated here, as it is validated once before the collection runs (see Validate); edges that would create a cycle are rejected by AddEdge.
+func addStepEdges(graph *dag.Graph[StepList], steps StepList) error {
 	// Background steps should only have an edge from the root node. This is automatically added as Background Steps do not have dependencies.
 	// Because Backgorund steps are intended to persist until the pipeline terminates, they can't have child steps.
 	if len(steps.Dependencies) == 0 {
-		pipeline.Graph.AddEdge(0, steps.ID)
+		if err := graph.AddEdge(0, steps.ID); err != nil {
+			return err
+		}
 	}
 
 	if steps.Type == StepTypeBackground {
@@ -239,8 +445,8 @@ func (c *Collection) AddSteps(pipelineID int64, steps StepList) error {
 	}
 
 	for _, parent := range steps.Dependencies {
-		if err := pipeline.Graph.AddEdge(parent.ID, steps.ID); err != nil {
-			return fmt.Errorf("error adding edges to pipeline graph: %w", err)
+		if err := graph.AddEdge(parent.ID, steps.ID); err != nil {
+			return err
 		}
 	}
 
@@ -248,6 +454,12 @@ func (c *Collection) AddSteps(pipelineID int64, steps StepList) error {
 }
 
 func (c *Collection) addPipeline(p Pipeline) error {
+	for _, v := range p.Dependencies {
+		if _, err := c.Graph.Node(v.ID); err != nil {
+			return fmt.Errorf("pipeline '%s' depends on pipeline '%s', which is not in the collection: %w", p.Name, v.Name, err)
+		}
+	}
+
 	if err := c.Graph.AddNode(p.ID, p); err != nil {
 		return fmt.Errorf("error adding new pipeline to graph: %w", err)
 	}
@@ -260,7 +472,7 @@ func (c *Collection) addPipeline(p Pipeline) error {
 
 	for _, v := range p.Dependencies {
 		if err := c.Graph.AddEdge(v.ID, p.ID); err != nil {
-			return err
+			return graphError(c.Graph, err, pipelineName)
 		}
 	}
 
@@ -274,6 +486,23 @@ func (c *Collection) AddPipelines(p ...Pipeline) error {
 			return err
 		}
 	}
+
+	return nil
+}
+
+// Validate ensures that the graph of pipelines and the graph of steps in every pipeline have no cycles, and that every pipeline and step can be reached from the root.
+// It walks every graph, so it is only called once the collection is complete, before it runs.
+func (c *Collection) Validate() error {
+	if err := c.Graph.Validate(0); err != nil {
+		return fmt.Errorf("invalid pipeline graph: %w", graphError(c.Graph, err, pipelineName))
+	}
+
+	for _, v := range c.Graph.Nodes {
+		if err := v.Value.Graph.Validate(0); err != nil {
+			return fmt.Errorf("invalid graph in pipeline '%s': %w", v.Value.Name, graphError(v.Value.Graph, err, stepListName))
+		}
+	}
+
 	return nil
 }
 
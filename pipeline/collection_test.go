@@ -2,8 +2,10 @@ package pipeline_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/scribe"
 	"github.com/grafana/scribe/pipeline"
 	"github.com/grafana/scribe/pipeline/clients"
@@ -53,6 +55,51 @@ func TestCollectionAddEvents(t *testing.T) {
 }
 
 func TestCollectionAddPipeline(t *testing.T) {
+	t.Run("AddPipelines should add an edge from the pipelines that a pipeline depends on", func(t *testing.T) {
+		col := pipeline.NewCollection()
+		first := pipeline.New("first", 1)
+		second := pipeline.New("second", 2)
+		second.Dependencies = []pipeline.Pipeline{first}
+
+		testutil.EnsureError(t, col.AddPipelines(first, second), nil)
+		dag.EnsureGraphEdges(t, map[int64][]int64{
+			0: {1},
+			1: {2},
+		}, col.Graph.Edges)
+	})
+
+	t.Run("AddPipelines should return an error if a pipeline depends on a pipeline that is not in the collection", func(t *testing.T) {
+		col := pipeline.NewCollection()
+		p := pipeline.New("second", 2)
+		p.Dependencies = []pipeline.Pipeline{pipeline.New("first", 1)}
+
+		testutil.EnsureError(t, col.AddPipelines(p), dag.ErrorNotFound)
+		if _, err := col.Graph.Node(2); err == nil {
+			t.Fatal("Expected the pipeline to not be added to the collection")
+		}
+	})
+}
+
+func TestCollectionValidate(t *testing.T) {
+	t.Run("Validate should return nil for a valid collection", func(t *testing.T) {
+		col, err := pipeline.NewCollectionWithSteps("test",
+			pipeline.NewStepList(1, pipeline.NoOpStep.WithName("a")),
+			pipeline.NewStepList(2, pipeline.NoOpStep.WithName("b")),
+		)
+		testutil.EnsureError(t, err, nil)
+		testutil.EnsureError(t, col.Validate(), nil)
+	})
+
+	t.Run("Validate should return ErrorUnreachable if steps in a pipeline can not be reached", func(t *testing.T) {
+		col, err := pipeline.NewCollectionWithSteps("test", pipeline.NewStepList(1, pipeline.NoOpStep.WithName("a")))
+		testutil.EnsureError(t, err, nil)
+
+		p, err := col.Graph.Node(1)
+		testutil.EnsureError(t, err, nil)
+		testutil.EnsureError(t, p.Value.Graph.AddNode(2, pipeline.NewStepList(2, pipeline.NoOpStep.WithName("b"))), nil)
+
+		testutil.EnsureError(t, col.Validate(), dag.ErrorUnreachable)
+	})
 }
 
 func TestCollectionAddSteps(t *testing.T) {
@@ -207,6 +254,100 @@ func TestCollectionAddSteps(t *testing.T) {
 
 		dag.EnsureGraphEdges(t, expectedEdges, g.Value.Graph.Edges)
 	})
+
+	t.Run("AddSteps should add an edge from the steps that produce an artifact to the steps that use it", func(t *testing.T) {
+		col := scribe.NewDefaultCollection(clients.CommonOpts{
+			Name: "test",
+		})
+
+		artifact := pipeline.NewArtifact("bin", "bin/*")
+
+		step1 := pipeline.StepList{
+			ID: 1,
+			Steps: []pipeline.Step{
+				pipeline.NoOpStep.WithName("build").WithOutput(artifact),
+			},
+		}
+
+		step2 := pipeline.StepList{
+			ID: 2,
+			Steps: []pipeline.Step{
+				pipeline.NoOpStep.WithName("lint"),
+			},
+		}
+
+		step3 := pipeline.StepList{
+			ID: 3,
+			Steps: []pipeline.Step{
+				pipeline.NoOpStep.WithName("package").WithInput(artifact),
+			},
+		}
+
+		testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, step1), nil)
+		testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, step2), nil)
+		testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, step3), nil)
+
+		expectedEdges := map[int64][]int64{
+			0: {1, 2},
+			1: {3},
+		}
+
+		g, _ := col.Graph.Node(scribe.DefaultPipelineID)
+		dag.EnsureGraphEdges(t, expectedEdges, g.Value.Graph.Edges)
+	})
+
+	t.Run("AddSteps should return an error if an artifact is produced and used by steps in the same list", func(t *testing.T) {
+		col := scribe.NewDefaultCollection(clients.CommonOpts{
+			Name: "test",
+		})
+
+		artifact := pipeline.NewArtifact("bin", "bin/*")
+
+		steps := pipeline.StepList{
+			ID: 1,
+			Steps: []pipeline.Step{
+				pipeline.NoOpStep.WithName("build").WithOutput(artifact),
+				pipeline.NoOpStep.WithName("package").WithInput(artifact),
+			},
+		}
+
+		if err := col.AddSteps(scribe.DefaultPipelineID, steps); err == nil {
+			t.Fatal("Expected an error but received none")
+		}
+	})
+
+	t.Run("AddSteps should add an edge from the steps in Step.After to the steps that run after them", func(t *testing.T) {
+		col := scribe.NewDefaultCollection(clients.CommonOpts{
+			Name: "test",
+		})
+
+		build := pipeline.NoOpStep.WithName("build")
+
+		step1 := pipeline.NewStepList(1, build)
+		step2 := pipeline.NewStepList(2, pipeline.NoOpStep.WithName("lint"))
+		step3 := pipeline.NewStepList(3, pipeline.NoOpStep.WithName("package").After(build))
+
+		testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, step1), nil)
+		testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, step2), nil)
+		testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, step3), nil)
+
+		expectedEdges := map[int64][]int64{
+			0: {1, 2},
+			1: {3},
+		}
+
+		g, _ := col.Graph.Node(scribe.DefaultPipelineID)
+		dag.EnsureGraphEdges(t, expectedEdges, g.Value.Graph.Edges)
+	})
+
+	t.Run("AddSteps should return an error if a step runs after a step that is not in the pipeline", func(t *testing.T) {
+		col := scribe.NewDefaultCollection(clients.CommonOpts{
+			Name: "test",
+		})
+
+		steps := pipeline.NewStepList(1, pipeline.NoOpStep.WithName("package").After(pipeline.NoOpStep.WithName("build")))
+		testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, steps), pipeline.ErrorDependencyNotFound)
+	})
 }
 
 func TestCollectionGetters(t *testing.T) {
@@ -299,5 +440,78 @@ func TestCollectionGetters(t *testing.T) {
 	})
 }
 
+func TestCollectionWalkSteps(t *testing.T) {
+	t.Run("WalkSteps should only visit the steps that run on failure after a step fails", func(t *testing.T) {
+		col := scribe.NewDefaultCollection(clients.CommonOpts{
+			Name: "test",
+		})
+
+		var (
+			a = pipeline.StepList{ID: 1, Steps: []pipeline.Step{{ID: 2, Name: "a"}}}
+			b = pipeline.StepList{ID: 3, Dependencies: []pipeline.StepList{a}, Steps: []pipeline.Step{{ID: 4, Name: "b"}}}
+			c = pipeline.StepList{ID: 5, Dependencies: []pipeline.StepList{b}, Steps: []pipeline.Step{pipeline.Step{ID: 6, Name: "c"}.OnFailure()}}
+			d = pipeline.StepList{ID: 7, Dependencies: []pipeline.StepList{c}, Steps: []pipeline.Step{pipeline.Step{ID: 8, Name: "d"}.Always()}}
+		)
+
+		for _, v := range []pipeline.StepList{a, b, c, d} {
+			testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, v), nil)
+		}
+
+		var (
+			errFailed = errors.New("failed")
+			visited   = []string{}
+		)
+
+		err := col.WalkSteps(context.Background(), scribe.DefaultPipelineID, func(ctx context.Context, steps ...pipeline.Step) error {
+			for _, v := range steps {
+				visited = append(visited, v.Name)
+				if v.Name == "a" {
+					return errFailed
+				}
+
+				if !errors.Is(pipeline.FailureFromContext(ctx), errFailed) {
+					t.Errorf("Expected the failure of step 'a' in the context of step '%s'", v.Name)
+				}
+			}
+			return nil
+		})
+
+		testutil.EnsureError(t, err, errFailed)
+		if diff := cmp.Diff([]string{"a", "c", "d"}, visited); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+	t.Run("WalkSteps should return the errors of every list of steps that failed", func(t *testing.T) {
+		col := scribe.NewDefaultCollection(clients.CommonOpts{
+			Name: "test",
+		})
+
+		var (
+			a = pipeline.StepList{ID: 1, Steps: []pipeline.Step{{ID: 2, Name: "a"}}}
+			b = pipeline.StepList{ID: 3, Dependencies: []pipeline.StepList{a}, Steps: []pipeline.Step{pipeline.Step{ID: 4, Name: "b"}.Always()}}
+		)
+
+		for _, v := range []pipeline.StepList{a, b} {
+			testutil.EnsureError(t, col.AddSteps(scribe.DefaultPipelineID, v), nil)
+		}
+
+		var (
+			errA = errors.New("a failed")
+			errB = errors.New("b failed")
+		)
+
+		err := col.WalkSteps(context.Background(), scribe.DefaultPipelineID, func(ctx context.Context, steps ...pipeline.Step) error {
+			if steps[0].Name == "a" {
+				return errA
+			}
+
+			return errB
+		})
+
+		testutil.EnsureError(t, err, errA)
+		testutil.EnsureError(t, err, errB)
+	})
+}
+
 func TestCollectionByName(t *testing.T) {
 }
@@ -55,7 +55,54 @@ func (g *Graph[T]) AddNode(id int64, v T) error {
 	return nil
 }
 
+// RemoveNode removes the node with the given ID and every edge from or to it.
+// If no node is found, ErrorNotFound is returned.
+func (g *Graph[T]) RemoveNode(id int64) error {
+	nodes := make([]Node[T], 0, len(g.Nodes))
+	for _, v := range g.Nodes {
+		if v.ID != id {
+			nodes = append(nodes, v)
+		}
+	}
+
+	if len(nodes) == len(g.Nodes) {
+		return fmt.Errorf("%w. id: %d", ErrorNotFound, id)
+	}
+
+	// The edges point to the nodes in the list, so they are added again with the new list.
+	index := make(map[int64]int, len(nodes))
+	for i, v := range nodes {
+		index[v.ID] = i
+	}
+
+	edges := make(map[int64][]Edge[T], len(g.Edges))
+	for from, list := range g.Edges {
+		fromIndex, ok := index[from]
+		if !ok {
+			continue
+		}
+
+		for _, e := range list {
+			// Edges to the removed node, or to nodes that are not in the graph, are dropped instead of pointing at the wrong node.
+			toIndex, ok := index[e.To.ID]
+			if !ok {
+				continue
+			}
+
+			edges[from] = append(edges[from], Edge[T]{
+				From: &nodes[fromIndex],
+				To:   &nodes[toIndex],
+			})
+		}
+	}
+
+	g.Nodes = nodes
+	g.Edges = edges
+	return nil
+}
+
 // AddEdge adds a new node from node with the ID 'from' to the node with the ID 'to'.
+// If the edge would create a cycle, then a *CycleError with the path of the cycle is returned and the edge is not added.
 func (g *Graph[T]) AddEdge(from, to int64) error {
 	var fromNode, toNode *Node[T]
 
@@ -78,6 +125,12 @@ func (g *Graph[T]) AddEdge(from, to int64) error {
 	if toNode == nil {
 		return fmt.Errorf("%w. id: %d", ErrorNotFound, to)
 	}
+
+	// If there is already a path from 'to' to 'from', then this edge would complete a cycle.
+	if path := g.Path(to, from); path != nil {
+		return &CycleError{Path: append([]int64{from}, path...)}
+	}
+
 	edges := g.Edges[from]
 	g.Edges[from] = append(edges, Edge[T]{
 		From: fromNode,
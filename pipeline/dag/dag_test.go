@@ -84,6 +84,52 @@ func TestGraphAddEdge(t *testing.T) {
 	})
 }
 
+func TestGraphRemoveNode(t *testing.T) {
+	t.Run("RemoveNode should remove the node and every edge from or to it", func(t *testing.T) {
+		g := dag.New[Node]()
+
+		testutil.EnsureError(t, g.AddNode(0, Node{}), nil)
+		testutil.EnsureError(t, g.AddNode(1, Node{}), nil)
+		testutil.EnsureError(t, g.AddNode(2, Node{}), nil)
+		testutil.EnsureError(t, g.AddNode(3, Node{}), nil)
+
+		testutil.EnsureError(t, g.AddEdge(0, 1), nil)
+		testutil.EnsureError(t, g.AddEdge(1, 2), nil)
+		testutil.EnsureError(t, g.AddEdge(2, 3), nil)
+		testutil.EnsureError(t, g.AddEdge(1, 3), nil)
+
+		testutil.EnsureError(t, g.RemoveNode(2), nil)
+		testutil.EnsureError(t, g.RemoveNode(2), dag.ErrorNotFound)
+
+		dag.EnsureGraphEdges(t, map[int64][]int64{
+			0: {1},
+			1: {3},
+		}, g.Edges)
+
+		EnsureNodesExist(t, g.Adj(1), 3)
+		testutil.EnsureError(t, g.Validate(0), nil)
+	})
+
+	t.Run("RemoveNode should drop edges from or to nodes that are not in the graph", func(t *testing.T) {
+		g := dag.New[Node]()
+
+		testutil.EnsureError(t, g.AddNode(0, Node{}), nil)
+		testutil.EnsureError(t, g.AddNode(1, Node{}), nil)
+		testutil.EnsureError(t, g.AddNode(2, Node{}), nil)
+
+		testutil.EnsureError(t, g.AddEdge(1, 2), nil)
+
+		g.Edges[1] = append(g.Edges[1], dag.Edge[Node]{From: &g.Nodes[1], To: &dag.Node[Node]{ID: 5}})
+		g.Edges[6] = []dag.Edge[Node]{{From: &dag.Node[Node]{ID: 6}, To: &g.Nodes[2]}}
+
+		testutil.EnsureError(t, g.RemoveNode(0), nil)
+
+		dag.EnsureGraphEdges(t, map[int64][]int64{
+			1: {2},
+		}, g.Edges)
+	})
+}
+
 func TestGraphAdj(t *testing.T) {
 	g := dag.New[Node]()
 
@@ -0,0 +1,216 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrorCycle       = errors.New("graph contains a cycle")
+	ErrorUnreachable = errors.New("node is not reachable from the root node")
+)
+
+// CycleError is returned when an edge would create a cycle in the graph, or when a cycle is found in a graph.
+// Path is the IDs of the nodes in the cycle; the first and last ID are the same.
+type CycleError struct {
+	Path []int64
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrorCycle.Error(), PathString(e.Path))
+}
+
+func (e *CycleError) Unwrap() error {
+	return ErrorCycle
+}
+
+// PathString formats the IDs in a path like '1 -> 2 -> 3'.
+func PathString(path []int64) string {
+	s := make([]string, len(path))
+	for i, v := range path {
+		s[i] = fmt.Sprint(v)
+	}
+
+	return strings.Join(s, " -> ")
+}
+
+// Path returns the IDs of the nodes in a path from the node with the ID 'from' to the node with the ID 'to', including both.
+// If there is no path, then nil is returned.
+func (g *Graph[T]) Path(from, to int64) []int64 {
+	var (
+		visited = map[int64]bool{}
+		walk    func(id int64) []int64
+	)
+
+	walk = func(id int64) []int64 {
+		if id == to {
+			return []int64{id}
+		}
+
+		visited[id] = true
+		for _, v := range g.Adj(id) {
+			if visited[v.ID] {
+				continue
+			}
+
+			if path := walk(v.ID); path != nil {
+				return append([]int64{id}, path...)
+			}
+		}
+
+		return nil
+	}
+
+	return walk(from)
+}
+
+// Cycle returns the IDs of the nodes in a cycle in the graph, with the first ID repeated at the end.
+// If the graph has no cycles, then nil is returned.
+func (g *Graph[T]) Cycle() []int64 {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		state = map[int64]int{}
+		stack = []int64{}
+		walk  func(id int64) []int64
+	)
+
+	walk = func(id int64) []int64 {
+		state[id] = visiting
+		stack = append(stack, id)
+
+		for _, v := range g.Adj(id) {
+			switch state[v.ID] {
+			case visiting:
+				// The node is already in the stack, so the path from it to the end of the stack is a cycle.
+				for i, s := range stack {
+					if s == v.ID {
+						return append(append([]int64{}, stack[i:]...), v.ID)
+					}
+				}
+			case unvisited:
+				if cycle := walk(v.ID); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+		return nil
+	}
+
+	for _, v := range g.Nodes {
+		if state[v.ID] == unvisited {
+			if cycle := walk(v.ID); cycle != nil {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// TopologicalSort returns every node in the graph in an order where each node comes after every node that has an edge to it.
+// Like a breadth-first search, nodes are visited level by level in the order that their edges were added, but a node is only visited once every node with an edge to it has been.
+// If the graph contains a cycle, then a *CycleError is returned.
+func (g *Graph[T]) TopologicalSort() ([]*Node[T], error) {
+	if cycle := g.Cycle(); cycle != nil {
+		return nil, &CycleError{Path: cycle}
+	}
+
+	parents := map[int64]int{}
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			parents[e.To.ID]++
+		}
+	}
+
+	queue := []int64{}
+	for _, v := range g.Nodes {
+		if parents[v.ID] == 0 {
+			queue = append(queue, v.ID)
+		}
+	}
+
+	nodes := make([]*Node[T], 0, len(g.Nodes))
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		node, err := g.Node(id)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		for _, v := range g.Adj(id) {
+			parents[v.ID]--
+			if parents[v.ID] == 0 {
+				queue = append(queue, v.ID)
+			}
+		}
+	}
+
+	return nodes, nil
+}
+
+// Unreachable returns the IDs of every node that can not be reached from the node with the ID 'root'.
+func (g *Graph[T]) Unreachable(root int64) []int64 {
+	reached := map[int64]bool{root: true}
+	queue := []int64{root}
+
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, v := range g.Adj(id) {
+			if !reached[v.ID] {
+				reached[v.ID] = true
+				queue = append(queue, v.ID)
+			}
+		}
+	}
+
+	ids := []int64{}
+	for _, v := range g.Nodes {
+		if !reached[v.ID] {
+			ids = append(ids, v.ID)
+		}
+	}
+
+	return ids
+}
+
+// Validate ensures that every edge in the graph connects nodes that are in the graph, that every node can be reached from the node with the ID 'root', and that the graph has no cycles.
+func (g *Graph[T]) Validate(root int64) error {
+	ids := map[int64]bool{}
+	for _, v := range g.Nodes {
+		ids[v.ID] = true
+	}
+
+	for from, edges := range g.Edges {
+		for _, e := range edges {
+			if !ids[from] {
+				return fmt.Errorf("edge from %d to %d. id: %d. error: %w", from, e.To.ID, from, ErrorNotFound)
+			}
+			if !ids[e.To.ID] {
+				return fmt.Errorf("edge from %d to %d. id: %d. error: %w", from, e.To.ID, e.To.ID, ErrorNotFound)
+			}
+		}
+	}
+
+	if cycle := g.Cycle(); cycle != nil {
+		return &CycleError{Path: cycle}
+	}
+
+	if ids := g.Unreachable(root); len(ids) != 0 {
+		return fmt.Errorf("ids: %v. error: %w", ids, ErrorUnreachable)
+	}
+
+	return nil
+}
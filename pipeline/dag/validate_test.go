@@ -0,0 +1,121 @@
+package dag_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grafana/scribe/pipeline/dag"
+	"github.com/grafana/scribe/testutil"
+)
+
+func newGraph(t *testing.T, nodes int64, edges map[int64][]int64) *dag.Graph[Node] {
+	t.Helper()
+	g := dag.New[Node]()
+	for i := int64(0); i < nodes; i++ {
+		testutil.EnsureError(t, g.AddNode(i, Node{}), nil)
+	}
+
+	for from, to := range edges {
+		for _, v := range to {
+			testutil.EnsureError(t, g.AddEdge(from, v), nil)
+		}
+	}
+
+	return g
+}
+
+func TestGraphCycles(t *testing.T) {
+	t.Run("AddEdge should return a CycleError with the path of the cycle", func(t *testing.T) {
+		g := newGraph(t, 4, map[int64][]int64{
+			0: {1},
+			1: {2},
+			2: {3},
+		})
+
+		err := g.AddEdge(3, 1)
+		testutil.EnsureError(t, err, dag.ErrorCycle)
+
+		cycle := &dag.CycleError{}
+		if !errors.As(err, &cycle) {
+			t.Fatalf("Expected a CycleError but got '%v'", err)
+		}
+		if !testutil.Int64SlicesEqual(cycle.Path, []int64{3, 1, 2, 3}) {
+			t.Fatalf("Unexpected cycle path '%v'", cycle.Path)
+		}
+
+		if len(g.Adj(3)) != 0 {
+			t.Fatal("Expected the edge that creates a cycle to not be added")
+		}
+	})
+
+	t.Run("AddEdge should return a CycleError for an edge from a node to itself", func(t *testing.T) {
+		g := newGraph(t, 2, nil)
+		testutil.EnsureError(t, g.AddEdge(1, 1), dag.ErrorCycle)
+	})
+
+	t.Run("Cycle should return nil for a graph without cycles", func(t *testing.T) {
+		g := newGraph(t, 4, map[int64][]int64{
+			0: {1, 2},
+			1: {3},
+			2: {3},
+		})
+
+		if cycle := g.Cycle(); cycle != nil {
+			t.Fatalf("Expected no cycle but got '%v'", cycle)
+		}
+	})
+}
+
+func TestGraphTopologicalSort(t *testing.T) {
+	t.Run("Nodes should come after every node with an edge to them", func(t *testing.T) {
+		// 3 is adjacent to 0, but also depends on 2, so it must be sorted after 2, unlike in a breadth-first search.
+		g := newGraph(t, 5, map[int64][]int64{
+			0: {1, 3, 4},
+			1: {2},
+			2: {3},
+		})
+
+		nodes, err := g.TopologicalSort()
+		testutil.EnsureError(t, err, nil)
+
+		ids := make([]int64, len(nodes))
+		for i, v := range nodes {
+			ids[i] = v.ID
+		}
+
+		if !testutil.Int64SlicesEqual(ids, []int64{0, 1, 4, 2, 3}) {
+			t.Fatalf("Unexpected order '%v'", ids)
+		}
+	})
+}
+
+func TestGraphValidate(t *testing.T) {
+	t.Run("Validate should return nil for a valid graph", func(t *testing.T) {
+		g := newGraph(t, 3, map[int64][]int64{
+			0: {1, 2},
+		})
+
+		testutil.EnsureError(t, g.Validate(0), nil)
+	})
+
+	t.Run("Validate should return ErrorUnreachable if a node can not be reached from the root", func(t *testing.T) {
+		g := newGraph(t, 3, map[int64][]int64{
+			0: {1},
+		})
+
+		if ids := g.Unreachable(0); !testutil.Int64SlicesEqual(ids, []int64{2}) {
+			t.Fatalf("Expected node '2' to be unreachable but got '%v'", ids)
+		}
+
+		testutil.EnsureError(t, g.Validate(0), dag.ErrorUnreachable)
+	})
+
+	t.Run("Validate should return ErrorNotFound if an edge connects to a node that is not in the graph", func(t *testing.T) {
+		g := newGraph(t, 2, map[int64][]int64{
+			0: {1},
+		})
+
+		g.Edges[1] = []dag.Edge[Node]{{From: &g.Nodes[1], To: &dag.Node[Node]{ID: 5}}}
+		testutil.EnsureError(t, g.Validate(0), dag.ErrorNotFound)
+	})
+}
@@ -1,8 +1,11 @@
 package pipeline
 
 import (
+	"errors"
 	"fmt"
+	"path"
 	"regexp"
+	"strings"
 
 	"github.com/grafana/scribe/state"
 )
@@ -25,6 +28,9 @@ const (
 type FilterValue struct {
 	Type  FilterValueType
 	Value fmt.Stringer
+
+	// Exclude inverts the filter, so that the event only matches values that do not match the filter. Use the 'Not' function to create an excluding filter.
+	Exclude bool
 }
 
 func (f *FilterValue) String() string {
@@ -52,6 +58,16 @@ func GlobFilter(v string) *FilterValue {
 	}
 }
 
+// Not returns a copy of the filter that matches every value that the filter does not match.
+// For example, 'Not(GlobFilter("release-*"))' matches every branch except release branches.
+func Not(f *FilterValue) *FilterValue {
+	return &FilterValue{
+		Type:    f.Type,
+		Value:   f.Value,
+		Exclude: !f.Exclude,
+	}
+}
+
 // Event is provided when defining a Scribe pipeline to define the events that cause the pipeline to be ran.
 // Some example events that might cause pipelines to be created:
 // * Manual events with user input, like 'Promotions' in Drone. In this scenario, the user may have the ability to supply any keys/values as arguments, however, pipeline developers in Scribe should be able to specifically define what fields are accepted. See https://docs.drone.io/promote/.
@@ -68,8 +84,17 @@ type Event struct {
 	Provides []state.Argument
 }
 
+// PathsChanged returns a filter that matches events that changed at least one file that matches the glob pattern.
+// It can be used as the 'Paths' filter of git commit and pull request events.
+// Patterns are matched against paths relative to the root of the repository, like 'pkg/**' or '*.md'.
+func PathsChanged(pattern string) *FilterValue {
+	return GlobFilter(pattern)
+}
+
 type GitCommitFilters struct {
 	Branch *FilterValue
+	// Paths filters the event by the files that were changed in the commit. See 'PathsChanged'.
+	Paths *FilterValue
 }
 
 // GitCommitEventArgs are arguments that should provide in the pipeline state when a pipeline was created from a git commit event.
@@ -77,6 +102,7 @@ var GitCommitEventArgs = []state.Argument{
 	ArgumentCommitSHA,
 	ArgumentBranch,
 	ArgumentRemoteURL,
+	ArgumentChangedFiles,
 }
 
 func GitCommitEvent(filters GitCommitFilters) Event {
@@ -86,6 +112,10 @@ func GitCommitEvent(filters GitCommitFilters) Event {
 		f["branch"] = filters.Branch
 	}
 
+	if filters.Paths != nil {
+		f["paths"] = filters.Paths
+	}
+
 	return Event{
 		Name:     "git-commit",
 		Filters:  f,
@@ -102,6 +132,7 @@ var GitTagEventArgs = []state.Argument{
 	ArgumentCommitSHA,
 	ArgumentCommitRef,
 	ArgumentRemoteURL,
+	ArgumentTagName,
 }
 
 func GitTagEvent(filters GitTagFilters) Event {
@@ -115,17 +146,261 @@ func GitTagEvent(filters GitTagFilters) Event {
 	}
 }
 
-type PullRequestFilters struct{}
+type PullRequestFilters struct {
+	// Branch filters the pull request by the branch that it will be merged into.
+	Branch *FilterValue
+	// Paths filters the pull request by the files that it changes. See 'PathsChanged'.
+	Paths *FilterValue
+}
 
 // PullRequestEventArgs are arguments that should provide in the pipeline state when a pipeline was created from a pull request.
-var PullRequestEventArgs = []state.Argument{}
+var PullRequestEventArgs = []state.Argument{
+	ArgumentCommitSHA,
+	ArgumentRemoteURL,
+	ArgumentPullRequestNumber,
+	ArgumentSourceBranch,
+	ArgumentTargetBranch,
+	ArgumentChangedFiles,
+}
 
 func PullRequestEvent(filters PullRequestFilters) Event {
 	f := map[string]*FilterValue{}
 
+	if filters.Branch != nil {
+		f["branch"] = filters.Branch
+	}
+
+	if filters.Paths != nil {
+		f["paths"] = filters.Paths
+	}
+
 	return Event{
 		Name:     "pull-request",
 		Filters:  f,
 		Provides: PullRequestEventArgs,
 	}
 }
+
+type CronFilters struct {
+	// Name filters the event by the name of the cron job that created it.
+	Name *FilterValue
+	// Schedule is the cron expression, like '0 4 * * *', that defines when the pipeline runs.
+	// Some CI services, like Drone, define schedules outside of the pipeline config and ignore this value.
+	Schedule string
+}
+
+// CronEventArgs are arguments that should provide in the pipeline state when a pipeline was created from a cron job.
+var CronEventArgs = []state.Argument{
+	ArgumentCommitSHA,
+	ArgumentBranch,
+	ArgumentRemoteURL,
+	ArgumentCronName,
+}
+
+// CronEvent creates an event for pipelines that run on a schedule.
+// The schedule is stored in the 'schedule' key of the event's filters so that clients that define schedules in the pipeline config can read it. It is not used to filter events.
+func CronEvent(filters CronFilters) Event {
+	f := map[string]*FilterValue{}
+
+	if filters.Name != nil {
+		f["cron"] = filters.Name
+	}
+
+	if filters.Schedule != "" {
+		f["schedule"] = StringFilter(filters.Schedule)
+	}
+
+	return Event{
+		Name:     "cron",
+		Filters:  f,
+		Provides: CronEventArgs,
+	}
+}
+
+type PromoteFilters struct {
+	// Target filters the event by the environment that the build is promoted to, like 'production'.
+	Target *FilterValue
+}
+
+// PromoteEventArgs are arguments that should provide in the pipeline state when a pipeline was created from a promotion.
+var PromoteEventArgs = []state.Argument{
+	ArgumentCommitSHA,
+	ArgumentRemoteURL,
+	ArgumentPromoteTarget,
+}
+
+// PromoteEvent creates an event for pipelines that run when a build is promoted, like 'Promotions' in Drone.
+// The params are the arguments that the user supplies when promoting the build. They are provided to the pipeline in addition to the PromoteEventArgs.
+func PromoteEvent(filters PromoteFilters, params ...state.Argument) Event {
+	f := map[string]*FilterValue{}
+
+	if filters.Target != nil {
+		f["target"] = filters.Target
+	}
+
+	provides := make([]state.Argument, 0, len(PromoteEventArgs)+len(params))
+	provides = append(provides, PromoteEventArgs...)
+	provides = append(provides, params...)
+
+	return Event{
+		Name:     "promote",
+		Filters:  f,
+		Provides: provides,
+	}
+}
+
+// ManualEventArgs are arguments that should provide in the pipeline state when a pipeline was created manually.
+var ManualEventArgs = []state.Argument{
+	ArgumentCommitSHA,
+	ArgumentBranch,
+	ArgumentRemoteURL,
+}
+
+// ManualEvent creates an event for pipelines that are started by a user, either in the CI service's UI or using its API.
+// The params are the arguments that the user supplies when starting the pipeline. They are provided to the pipeline in addition to the ManualEventArgs.
+func ManualEvent(params ...state.Argument) Event {
+	provides := make([]state.Argument, 0, len(ManualEventArgs)+len(params))
+	provides = append(provides, ManualEventArgs...)
+	provides = append(provides, params...)
+
+	return Event{
+		Name:     "manual",
+		Filters:  map[string]*FilterValue{},
+		Provides: provides,
+	}
+}
+
+var (
+	ErrorEventMismatch = errors.New("event does not match filter")
+)
+
+// eventFilterArguments maps the filters of every event to the argument that the filter is matched against.
+// Filters that are not in this map, like the 'schedule' of a cron event, are not used when matching an event.
+var eventFilterArguments = map[string]map[string]state.Argument{
+	"git-commit": {
+		"branch": ArgumentBranch,
+		"paths":  ArgumentChangedFiles,
+	},
+	"git-tag": {
+		"tag": ArgumentTagName,
+	},
+	"pull-request": {
+		"branch": ArgumentTargetBranch,
+		"paths":  ArgumentChangedFiles,
+	},
+	"cron": {
+		"cron": ArgumentCronName,
+	},
+	"promote": {
+		"target": ArgumentPromoteTarget,
+	},
+}
+
+// globRegexp converts a glob pattern into a regular expression. Like in 'path.Match', '*' does not match '/', however '**' matches any sequence of characters, including '/'.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	b := strings.Builder{}
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
+
+// Match returns true if the value matches the filter.
+// If the filter is an excluding filter, then it returns true if the value does not match.
+func (f *FilterValue) Match(value string) (bool, error) {
+	var matched bool
+
+	switch f.Type {
+	case FilterValueRegex:
+		re, ok := f.Value.(*regexp.Regexp)
+		if !ok {
+			r, err := regexp.Compile(f.String())
+			if err != nil {
+				return false, err
+			}
+			re = r
+		}
+		matched = re.MatchString(value)
+	case FilterValueGlob:
+		if strings.Contains(f.String(), "**") {
+			re, err := globRegexp(f.String())
+			if err != nil {
+				return false, err
+			}
+			matched = re.MatchString(value)
+			break
+		}
+		m, err := path.Match(f.String(), value)
+		if err != nil {
+			return false, err
+		}
+		matched = m
+	default:
+		matched = f.String() == value
+	}
+
+	return matched != f.Exclude, nil
+}
+
+// Match checks the filters of the event against the values of the arguments that the event provides. The values are keyed by the argument key.
+// It returns nil if every filter matches, or an error that wraps ErrorEventMismatch and describes the filter that did not match.
+// Because 'paths' filters are matched against a newline-separated list of changed files, they match if at least one of the files matches.
+func (e Event) Match(values map[string]string) error {
+	for key, f := range e.Filters {
+		arg, ok := eventFilterArguments[e.Name][key]
+		if !ok || f == nil {
+			continue
+		}
+
+		value, ok := values[arg.Key]
+		if !ok {
+			return fmt.Errorf("%w: no value for argument '%s' to match the '%s' filter", ErrorEventMismatch, arg.Key, key)
+		}
+
+		candidates := []string{value}
+		if key == "paths" {
+			candidates = strings.Split(strings.TrimSpace(value), "\n")
+		}
+
+		matched := false
+		for _, v := range candidates {
+			m, err := f.Match(strings.TrimSpace(v))
+			if err != nil {
+				return err
+			}
+			if m {
+				matched = true
+				break
+			}
+		}
+
+		if !matched && key == "paths" {
+			return fmt.Errorf("%w: none of the changed files match '%s'", ErrorEventMismatch, f.String())
+		}
+
+		if !matched {
+			prefix := ""
+			if f.Exclude {
+				prefix = "not "
+			}
+			return fmt.Errorf("%w: '%s' value '%s' does not match '%s%s'", ErrorEventMismatch, key, value, prefix, f.String())
+		}
+	}
+
+	return nil
+}
@@ -1,7 +1,11 @@
 package pipeline_test
 
 import (
+	"errors"
+	"regexp"
 	"testing"
+
+	"github.com/grafana/scribe/pipeline"
 )
 
 func TestManualEvents(t *testing.T) {
@@ -20,3 +24,100 @@ func TestManualEvents(t *testing.T) {
 	// 	}
 	// })
 }
+
+func TestFilterValueMatch(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Filter   *pipeline.FilterValue
+		Value    string
+		Expected bool
+	}{
+		{"String filters should match equal values", pipeline.StringFilter("main"), "main", true},
+		{"String filters should not match other values", pipeline.StringFilter("main"), "mainline", false},
+		{"Regex filters should match values that match the expression", pipeline.RegexpFilter(regexp.MustCompile(`^v\d+\.\d+$`)), "v1.2", true},
+		{"Glob filters should match values that match the pattern", pipeline.GlobFilter("release-*"), "release-1.0", true},
+		{"Single star glob filters should not match a '/'", pipeline.GlobFilter("docs/*"), "docs/a/b.md", false},
+		{"Double star glob filters should match a '/'", pipeline.GlobFilter("docs/**"), "docs/a/b.md", true},
+		{"Excluding filters should match values that the filter does not match", pipeline.Not(pipeline.StringFilter("main")), "feature", true},
+		{"Excluding filters should not match values that the filter matches", pipeline.Not(pipeline.StringFilter("main")), "main", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			matched, err := c.Filter.Match(c.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if matched != c.Expected {
+				t.Fatalf("Expected '%t' but got '%t'", c.Expected, matched)
+			}
+		})
+	}
+}
+
+func TestEventMatch(t *testing.T) {
+	t.Run("Events should match values that match every filter", func(t *testing.T) {
+		e := pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+			Branch: pipeline.StringFilter("main"),
+			Paths:  pipeline.PathsChanged("docs/**"),
+		})
+
+		err := e.Match(map[string]string{
+			pipeline.ArgumentBranch.Key:       "main",
+			pipeline.ArgumentChangedFiles.Key: "go.mod\ndocs/index.md",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Events should not match if a filter does not match", func(t *testing.T) {
+		e := pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+			Paths: pipeline.PathsChanged("docs/**"),
+		})
+
+		err := e.Match(map[string]string{
+			pipeline.ArgumentChangedFiles.Key: "go.mod\ngo.sum",
+		})
+		if !errors.Is(err, pipeline.ErrorEventMismatch) {
+			t.Fatalf("Expected error '%v' but got '%v'", pipeline.ErrorEventMismatch, err)
+		}
+	})
+
+	t.Run("Events should not match if there is no value for a filter", func(t *testing.T) {
+		e := pipeline.GitTagEvent(pipeline.GitTagFilters{
+			Name: pipeline.GlobFilter("v*"),
+		})
+
+		if err := e.Match(map[string]string{}); !errors.Is(err, pipeline.ErrorEventMismatch) {
+			t.Fatalf("Expected error '%v' but got '%v'", pipeline.ErrorEventMismatch, err)
+		}
+	})
+
+	t.Run("The schedule of a cron event should not be used as a filter", func(t *testing.T) {
+		e := pipeline.CronEvent(pipeline.CronFilters{
+			Schedule: "0 4 * * *",
+		})
+
+		if err := e.Match(map[string]string{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Pipelines should match if any of their events with the name match", func(t *testing.T) {
+		p := pipeline.New("test", 1)
+		p.Events = []pipeline.Event{
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{Branch: pipeline.StringFilter("main")}),
+			pipeline.GitCommitEvent(pipeline.GitCommitFilters{Branch: pipeline.GlobFilter("release-*")}),
+		}
+
+		if err := p.MatchEvent("git-commit", map[string]string{pipeline.ArgumentBranch.Key: "release-1.0"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := p.MatchEvent("git-tag", map[string]string{}); !errors.Is(err, pipeline.ErrorEventNotFound) {
+			t.Fatalf("Expected error '%v' but got '%v'", pipeline.ErrorEventNotFound, err)
+		}
+	})
+}
@@ -1,6 +1,15 @@
 package pipeline
 
-import "github.com/grafana/scribe/pipeline/dag"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/scribe/pipeline/dag"
+)
+
+var (
+	ErrorEventNotFound = errors.New("pipeline does not run on event")
+)
 
 // A Pipeline is really similar to a Step, except that it contains a graph of steps rather than
 // a single action. Just like a Step, it has dependencies, a name, an ID, etc.
@@ -11,6 +20,10 @@ type Pipeline struct {
 	Events       []Event
 	Type         PipelineType
 	Dependencies []Pipeline
+
+	// Detached is true for sub-pipelines that do not block the pipeline from finishing. Their failures are logged, but do not fail the pipeline,
+	// and they are stopped once the rest of the pipeline is done.
+	Detached bool
 }
 
 // New creates a new Step that represents a pipeline.
@@ -27,6 +40,23 @@ func New(name string, id int64) Pipeline {
 	}
 }
 
+// MatchEvent returns nil if any of the pipeline's events with the name match the values. See 'Event.Match'.
+// If the pipeline has no events with the name, then ErrorEventNotFound is returned.
+func (p Pipeline) MatchEvent(name string, values map[string]string) error {
+	err := fmt.Errorf("%w: '%s'", ErrorEventNotFound, name)
+	for _, e := range p.Events {
+		if e.Name != name {
+			continue
+		}
+
+		if err = e.Match(values); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
 func PipelineNames(s []Pipeline) []string {
 	v := make([]string, len(s))
 	for i := range s {
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
+	"github.com/grafana/scribe/cache"
 	"github.com/grafana/scribe/state"
 	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
 )
 
 type (
@@ -44,6 +47,12 @@ type ActionOpts struct {
 	// Version refers to the version of Scribe that was used to run the pipeline.
 	// This value is set using the `-version` argument when running a pipeline, which is automatically set by the `scribe` command.
 	Version string
+
+	// Step is the step that is running this action.
+	// Actions that wrap other actions, like the ones created with CacheAction, use it to read the image and arguments of the step.
+	Step Step
+	// Cache is where cached step outputs are stored. It is configured using the `-cache` argument and may be nil if caching is disabled.
+	Cache cache.Store
 }
 
 // A Step stores a Action and a name for use in pipelines.
@@ -79,7 +88,38 @@ type Step struct {
 	// Provides are arguments that this step provides for other arguments to use in their "Arguments" list.
 	ProvidesArgs []state.Argument
 
+	// Inputs are artifacts that this step uses. They are extracted into the working directory before the step runs.
+	Inputs []Artifact
+
+	// Outputs are artifacts that this step produces. They are packaged and stored in the state after the step runs.
+	Outputs []Artifact
+
 	Environment StepEnv
+
+	// Timeout is how long the action of the step can run before it is cancelled. If it is 0, then the step has no timeout.
+	Timeout time.Duration
+
+	// Retry defines how many times the action of the step is ran again if it fails.
+	Retry Retry
+
+	// timeoutSet and retrySet are true if the step set its timeout or retries with WithTimeout or WithRetry, even to 0, so that defaults are not applied to it.
+	timeoutSet bool
+	retrySet   bool
+
+	// RunOn defines whether the step runs depending on the outcome of the steps before it. See OnFailure and Always.
+	RunOn RunCondition
+
+	// Conditions are checked before the step runs. If any of them return false, then the step is skipped. See When.
+	Conditions []ConditionFunc
+
+	// Events are the events that the step runs for. If it is empty, then the step runs for every event of its pipeline. See WhenEvent.
+	Events []Event
+
+	// FailureAllowed is true if the step can fail without failing the pipeline. See AllowFailure.
+	FailureAllowed bool
+
+	// Resources are hints about what the step uses while it runs, which clients use to decide how many steps run at the same time. See WithCPU and WithLock.
+	Resources Resources
 }
 
 func (s Step) IsBackground() bool {
@@ -96,17 +136,33 @@ func (s Step) After(step Step) Step {
 	return s
 }
 
+// AllowFailure lets the step fail without failing the pipeline. The error is logged, and the steps after it run as if it succeeded.
+func (s Step) AllowFailure() Step {
+	s.FailureAllowed = true
+	return s
+}
+
 func (s Step) WithImage(image string) Step {
 	s.Image = image
 	return s
 }
 
+// WithOutput declares that this step produces the artifact.
+// After the step runs, the files that make up the artifact are packaged and stored in the state so that other steps can use them with WithInput.
+// The artifact's argument is also added to this step's provided arguments.
 func (s Step) WithOutput(artifact Artifact) Step {
+	s.Outputs = append(s.Outputs, artifact)
+	s.ProvidesArgs = append(s.ProvidesArgs, artifact.Argument())
 	return s
 }
 
+// WithInput declares that this step uses an artifact produced by another step.
+// Before the step runs, the artifact is extracted into its working directory.
+// The step that produces the artifact is automatically added as a dependency of this step when it is added to a collection.
+// The artifact's argument is also added to this step's required arguments.
 func (s Step) WithInput(artifact Artifact) Step {
-	return s
+	s.Inputs = append(s.Inputs, artifact)
+	return s.Requires(artifact.Argument())
 }
 
 // WithEnvVar appends a new EnvVar to the Step's environment, replacing existing EnvVars with the provided key.
@@ -193,22 +249,66 @@ var NoOpStep = Step{
 	},
 }
 
-// Combine combines the list of steps into one step, combining all of their required and provided arguments, as well as their actions.
-// For string values that can not be combined, like Name and Image, the first step's values are chosen.
+// Combine combines the list of steps into one step, combining all of their required and provided arguments and artifacts, as well as their actions.
+// The combined step has the longest timeout and the most retries of the steps, every one of their locks, conditions, and events, and the highest CPU weight. It can only fail without failing the pipeline if every step can.
+// For string values that can not be combined, like Name and Image, the first step's values are chosen. This is also true of RunOn.
 // These can be overridden with further chaining.
 func Combine(step ...Step) Step {
 	s := Step{
-		Name:         step[0].Name,
-		Image:        step[0].Image,
-		Dependencies: []Step{},
-		Arguments:    []state.Argument{},
-		ProvidesArgs: []state.Argument{},
+		Name:           step[0].Name,
+		Image:          step[0].Image,
+		RunOn:          step[0].RunOn,
+		Timeout:        step[0].Timeout,
+		FailureAllowed: true,
+		Dependencies:   []Step{},
+		Arguments:      []state.Argument{},
+		ProvidesArgs:   []state.Argument{},
+		Inputs:         []Artifact{},
+		Outputs:        []Artifact{},
+		Conditions:     []ConditionFunc{},
+		Events:         []Event{},
 	}
 
+	// If any of the steps can run for every event, then so can the combined step.
+	everyEvent := false
+
 	for _, v := range step {
 		s.Dependencies = append(s.Dependencies, v.Dependencies...)
 		s.Arguments = append(s.Arguments, v.Arguments...)
 		s.ProvidesArgs = append(s.ProvidesArgs, v.ProvidesArgs...)
+		s.Inputs = append(s.Inputs, v.Inputs...)
+		s.Outputs = append(s.Outputs, v.Outputs...)
+		s.Conditions = append(s.Conditions, v.Conditions...)
+		s.Events = append(s.Events, v.Events...)
+		everyEvent = everyEvent || len(v.Events) == 0
+
+		// A timeout of 0 means that the step has no timeout, so the combined step does not have one either.
+		if s.Timeout != 0 && (v.Timeout == 0 || v.Timeout > s.Timeout) {
+			s.Timeout = v.Timeout
+		}
+		if v.Retry.Retries > s.Retry.Retries {
+			s.Retry.Retries = v.Retry.Retries
+		}
+		if v.Retry.Backoff > s.Retry.Backoff {
+			s.Retry.Backoff = v.Retry.Backoff
+		}
+
+		if v.Resources.CPU > s.Resources.CPU {
+			s.Resources.CPU = v.Resources.CPU
+		}
+		for _, lock := range v.Resources.Locks {
+			if !slices.Contains(s.Resources.Locks, lock) {
+				s.Resources.Locks = append(s.Resources.Locks, lock)
+			}
+		}
+
+		s.FailureAllowed = s.FailureAllowed && v.FailureAllowed
+		s.timeoutSet = s.timeoutSet || v.timeoutSet
+		s.retrySet = s.retrySet || v.retrySet
+	}
+
+	if everyEvent {
+		s.Events = []Event{}
 	}
 
 	s.Action = func(ctx context.Context, opts ActionOpts) error {
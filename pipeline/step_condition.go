@@ -0,0 +1,169 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/scribe/state"
+)
+
+var (
+	ErrorStepSkipped = errors.New("step skipped")
+)
+
+// RunCondition defines whether a step runs depending on the outcome of the steps that ran before it.
+type RunCondition int
+
+const (
+	// RunOnSuccess runs the step only if every step before it succeeded. This is the default.
+	RunOnSuccess RunCondition = iota
+	// RunOnFailure runs the step only if a step before it failed.
+	RunOnFailure
+	// RunAlways runs the step whether or not a step before it failed.
+	RunAlways
+)
+
+// PipelineStatus is the outcome of the steps that ran before a step.
+type PipelineStatus int
+
+const (
+	// StatusUnknown is used when the steps before a step did not run in the same process, like when a single step is ran with the '-step' argument.
+	// In that case, whatever ran the step, like a CI service, has already checked the step's RunCondition.
+	StatusUnknown PipelineStatus = iota
+	StatusSuccess
+	StatusFailure
+)
+
+// ConditionFunc returns true if the step should run. It can read the values of arguments from the state.
+type ConditionFunc func(*state.State) bool
+
+// OnFailure runs the step only if a step before it failed, like a step that sends a notification.
+func (s Step) OnFailure() Step {
+	s.RunOn = RunOnFailure
+	return s
+}
+
+// Always runs the step whether or not a step before it failed, like a step that cleans up after the pipeline.
+func (s Step) Always() Step {
+	s.RunOn = RunAlways
+	return s
+}
+
+// When adds a condition to the step. The condition is checked right before the step runs, and the step is skipped if it returns false.
+func (s Step) When(f ConditionFunc) Step {
+	s.Conditions = append(s.Conditions, f)
+	return s
+}
+
+// WhenEvent runs the step only for the events, like a deploy step that only runs for commits to the 'main' branch.
+// The events use the same filters as the events of a pipeline (see Scribe.When), but the step is skipped instead of the whole pipeline.
+func (s Step) WhenEvent(events ...Event) Step {
+	s.Events = append(s.Events, events...)
+	return s
+}
+
+type failureKey struct{}
+
+// ContextWithFailure returns a copy of the context that records that a step in the pipeline failed with 'err'.
+// It is used by the Walker to let the steps after the failure check their RunCondition.
+func ContextWithFailure(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, failureKey{}, err)
+}
+
+// FailureFromContext returns the error of the step that failed before the current step, or nil if no step has failed.
+func FailureFromContext(ctx context.Context) error {
+	err, _ := ctx.Value(failureKey{}).(error)
+	return err
+}
+
+// StatusFromContext returns StatusFailure if a step before the current step failed, and StatusSuccess otherwise.
+func StatusFromContext(ctx context.Context) PipelineStatus {
+	if FailureFromContext(ctx) != nil {
+		return StatusFailure
+	}
+
+	return StatusSuccess
+}
+
+// RunsOnFailure returns true if the step runs after a step before it failed.
+func (s Step) RunsOnFailure() bool {
+	return s.RunOn == RunOnFailure || s.RunOn == RunAlways
+}
+
+// CheckStatus returns an error that wraps ErrorStepSkipped if the step's RunCondition does not match the status of the steps before it.
+func (s Step) CheckStatus(status PipelineStatus) error {
+	switch {
+	case status == StatusFailure && !s.RunsOnFailure():
+		return fmt.Errorf("%w: a previous step failed", ErrorStepSkipped)
+	case status == StatusSuccess && s.RunOn == RunOnFailure:
+		return fmt.Errorf("%w: the step only runs if a previous step failed", ErrorStepSkipped)
+	}
+
+	return nil
+}
+
+// CheckEvent returns an error that wraps ErrorStepSkipped if the step was limited with WhenEvent and none of its events match the event with the name 'event'.
+// The values of the event's filters are read from the state. If 'event' is empty, then the event is not known, like when a CI service has already checked it, and nil is returned.
+func (s Step) CheckEvent(event string, st *state.State) error {
+	if event == "" || len(s.Events) == 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("%w: the step does not run on event '%s'", ErrorStepSkipped, event)
+	for _, e := range s.Events {
+		if e.Name != event {
+			continue
+		}
+
+		values := map[string]string{}
+		for _, arg := range eventFilterArguments[e.Name] {
+			if st == nil {
+				break
+			}
+			if v, err := st.Handler.GetString(arg); err == nil {
+				values[arg.Key] = v
+			}
+		}
+
+		if err = e.Match(values); err == nil {
+			return nil
+		}
+		err = fmt.Errorf("%w: %s", ErrorStepSkipped, err)
+	}
+
+	return err
+}
+
+// CheckConditions returns an error that wraps ErrorStepSkipped if any of the conditions added with When return false.
+func (s Step) CheckConditions(st *state.State) error {
+	for i, f := range s.Conditions {
+		if !f(st) {
+			return fmt.Errorf("%w: condition %d returned false", ErrorStepSkipped, i+1)
+		}
+	}
+
+	return nil
+}
+
+// ConditionOpts are the values that the conditions of a step are checked against.
+type ConditionOpts struct {
+	State  *state.State
+	Status PipelineStatus
+	// Event is the name of the event that the pipeline is running for, or an empty string if it is not known.
+	Event string
+}
+
+// Skip checks the step's RunCondition, events, and conditions, in that order.
+// If the step should not run, then an error that wraps ErrorStepSkipped and describes why is returned.
+func (s Step) Skip(opts ConditionOpts) error {
+	if err := s.CheckStatus(opts.Status); err != nil {
+		return err
+	}
+
+	if err := s.CheckEvent(opts.Event, opts.State); err != nil {
+		return err
+	}
+
+	return s.CheckConditions(opts.State)
+}
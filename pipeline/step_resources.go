@@ -0,0 +1,32 @@
+package pipeline
+
+// Resources are hints about what a step uses while it runs.
+// Clients that run every step on the same machine, like the CLI and Dagger clients, use them to decide how many steps can run at the same time.
+type Resources struct {
+	// CPU is the weight of the step compared to other steps, roughly the number of CPUs that it keeps busy. If it is less than 1, then the step has a weight of 1.
+	CPU int
+	// Locks are the names of resources that only one step can use at a time, like "docker". Steps that share a lock never run at the same time.
+	Locks []string
+}
+
+// WithCPU sets the weight of the step, like '4' for a step that runs a 'go build' that uses 4 CPUs.
+// Steps are not started while the weight of the steps that are running would be more than the '-parallelism' argument.
+func (s Step) WithCPU(weight int) Step {
+	s.Resources.CPU = weight
+	return s
+}
+
+// WithLock prevents the step from running at the same time as any other step that has one of the locks, like "docker" for steps that build or push images.
+func (s Step) WithLock(names ...string) Step {
+	s.Resources.Locks = append(s.Resources.Locks, names...)
+	return s
+}
+
+// Weight returns the weight of the step (see WithCPU), which is at least 1.
+func (s Step) Weight() int {
+	if s.Resources.CPU < 1 {
+		return 1
+	}
+
+	return s.Resources.CPU
+}
@@ -0,0 +1,97 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/scribe/pipeline/dag"
+)
+
+var (
+	ErrorDependencyNotFound = errors.New("step depends on a step that is not in the pipeline")
+)
+
+// matchesDependency returns true if the step is the dependency 'dep' that was added with Step.After.
+// Steps are matched by ID, or by name if the dependency has no ID, like when After was used before the step was added to the pipeline.
+func (s Step) matchesDependency(dep Step) bool {
+	if dep.ID != 0 {
+		return s.ID == dep.ID
+	}
+
+	return dep.Name != "" && s.Name == dep.Name
+}
+
+// providesArgument returns true if the step provides an argument with the key.
+func (s Step) providesArgument(key string) bool {
+	for _, v := range s.ProvidesArgs {
+		if v.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SortSteps orders the steps using the arguments that they require and provide, and the steps that they run after with Step.After.
+// 'deps' has the indexes of the steps that every step must run after, and 'order' has the index of every step in an order where each step comes after its dependencies.
+// Steps that do not depend on each other keep the order that they were provided in.
+// If the steps depend on each other in a cycle, then an error that wraps dag.ErrorCycle and lists the steps in the cycle is returned.
+func SortSteps(steps []Step) (order []int, deps [][]int, err error) {
+	deps = make([][]int, len(steps))
+	for i, step := range steps {
+		for j, v := range steps {
+			if i == j {
+				continue
+			}
+
+			depends := false
+			for _, arg := range step.Arguments {
+				if v.providesArgument(arg.Key) && !step.providesArgument(arg.Key) {
+					depends = true
+					break
+				}
+			}
+
+			for _, d := range step.Dependencies {
+				if v.matchesDependency(d) {
+					depends = true
+					break
+				}
+			}
+
+			if depends {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+
+	// The ID of each node in the graph is the index of the step.
+	graph := dag.New[Step]()
+	for i, v := range steps {
+		if err := graph.AddNode(int64(i), v); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for i := range steps {
+		for _, d := range deps[i] {
+			if err := graph.AddEdge(int64(d), int64(i)); err != nil {
+				return nil, nil, graphError(graph, err, func(s Step) string {
+					return fmt.Sprintf("'%s'", s.Name)
+				})
+			}
+		}
+	}
+
+	nodes, err := graph.TopologicalSort()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order = make([]int, len(nodes))
+	for i, v := range nodes {
+		order[i] = int(v.ID)
+	}
+
+	return order, deps, nil
+}
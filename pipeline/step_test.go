@@ -1,9 +1,15 @@
 package pipeline_test
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
 )
 
 func TestStepIsBackground(t *testing.T) {
@@ -14,3 +20,158 @@ func TestStepIsBackground(t *testing.T) {
 		t.Fatal("step.IsBackground should return true if the step.Type is pipeline.StepTypeBackground")
 	}
 }
+
+func TestStepMaxDuration(t *testing.T) {
+	t.Run("MaxDuration should return 0 if the step has no timeout", func(t *testing.T) {
+		step := pipeline.NamedStep("test step", pipeline.DefaultAction).WithRetry(2, time.Second)
+		if d := step.MaxDuration(); d != 0 {
+			t.Fatalf("Expected 0 but got '%s'", d)
+		}
+	})
+
+	t.Run("MaxDuration should include every attempt and the backoff between them", func(t *testing.T) {
+		step := pipeline.NamedStep("test step", pipeline.DefaultAction).
+			WithTimeout(time.Minute).
+			WithRetry(2, time.Second)
+
+		// 3 attempts, with 1 second before the first retry and 2 seconds before the second.
+		expected := 3*time.Minute + 3*time.Second
+		if d := step.MaxDuration(); d != expected {
+			t.Fatalf("Expected '%s' but got '%s'", expected, d)
+		}
+	})
+}
+
+func TestCombine(t *testing.T) {
+	t.Run("Combine should merge the timeouts, retries, resources, conditions, events, and allowed failures of the steps", func(t *testing.T) {
+		var (
+			condition = func(*state.State) bool { return true }
+			a         = pipeline.NamedStep("a", pipeline.DefaultAction).
+					WithTimeout(time.Minute).
+					WithRetry(1, 2*time.Second).
+					WithCPU(2).
+					WithLock("docker").
+					When(condition).
+					WhenEvent(pipeline.GitTagEvent(pipeline.GitTagFilters{})).
+					AllowFailure()
+			b = pipeline.NamedStep("b", pipeline.DefaultAction).
+				WithTimeout(2*time.Minute).
+				WithRetry(3, time.Second).
+				WithCPU(1).
+				WithLock("docker", "network").
+				When(condition).
+				WhenEvent(pipeline.GitCommitEvent(pipeline.GitCommitFilters{})).
+				OnFailure()
+		)
+
+		step := pipeline.Combine(a, b)
+
+		if step.Timeout != 2*time.Minute {
+			t.Errorf("Expected the timeout to be '%s' but got '%s'", 2*time.Minute, step.Timeout)
+		}
+		if diff := cmp.Diff(pipeline.Retry{Retries: 3, Backoff: 2 * time.Second}, step.Retry); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff(pipeline.Resources{CPU: 2, Locks: []string{"docker", "network"}}, step.Resources); diff != "" {
+			t.Error(diff)
+		}
+		if len(step.Conditions) != 2 {
+			t.Errorf("Expected 2 conditions but got %d", len(step.Conditions))
+		}
+		if len(step.Events) != 2 {
+			t.Errorf("Expected 2 events but got %d", len(step.Events))
+		}
+		if step.RunOn != pipeline.RunOnSuccess {
+			t.Errorf("Expected the RunOn of the first step but got '%d'", step.RunOn)
+		}
+		if step.FailureAllowed {
+			t.Error("Expected the combined step to not allow failure, as only one of the steps does")
+		}
+	})
+
+	t.Run("Combine should not have a timeout or events if one of the steps does not", func(t *testing.T) {
+		var (
+			a = pipeline.NamedStep("a", pipeline.DefaultAction).
+				WithTimeout(time.Minute).
+				WhenEvent(pipeline.GitTagEvent(pipeline.GitTagFilters{}))
+			b = pipeline.NamedStep("b", pipeline.DefaultAction)
+		)
+
+		step := pipeline.Combine(a, b)
+		if step.Timeout != 0 {
+			t.Errorf("Expected no timeout but got '%s'", step.Timeout)
+		}
+		if len(step.Events) != 0 {
+			t.Errorf("Expected no events but got %d", len(step.Events))
+		}
+	})
+}
+
+func newState(t *testing.T) *state.State {
+	t.Helper()
+	h, err := state.NewFilesystemState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &state.State{
+		Handler: h,
+		Log:     logrus.New(),
+	}
+}
+
+func TestStepSkip(t *testing.T) {
+	t.Run("Steps should be skipped if their RunCondition does not match the status", func(t *testing.T) {
+		var (
+			step      = pipeline.NamedStep("step", pipeline.DefaultAction)
+			onFailure = step.OnFailure()
+			always    = step.Always()
+		)
+
+		testutil.EnsureError(t, step.Skip(pipeline.ConditionOpts{Status: pipeline.StatusSuccess}), nil)
+		testutil.EnsureError(t, step.Skip(pipeline.ConditionOpts{Status: pipeline.StatusFailure}), pipeline.ErrorStepSkipped)
+		testutil.EnsureError(t, onFailure.Skip(pipeline.ConditionOpts{Status: pipeline.StatusSuccess}), pipeline.ErrorStepSkipped)
+		testutil.EnsureError(t, onFailure.Skip(pipeline.ConditionOpts{Status: pipeline.StatusFailure}), nil)
+		testutil.EnsureError(t, always.Skip(pipeline.ConditionOpts{Status: pipeline.StatusSuccess}), nil)
+		testutil.EnsureError(t, always.Skip(pipeline.ConditionOpts{Status: pipeline.StatusFailure}), nil)
+
+		// If the status is not known, then it was already checked by whatever ran the step.
+		testutil.EnsureError(t, onFailure.Skip(pipeline.ConditionOpts{Status: pipeline.StatusUnknown}), nil)
+	})
+
+	t.Run("Steps should be skipped if a condition returns false", func(t *testing.T) {
+		s := newState(t)
+		arg := state.NewBoolArgument("deploy")
+		step := pipeline.NamedStep("step", pipeline.DefaultAction).When(func(s *state.State) bool {
+			v, err := s.GetBool(arg)
+			return err == nil && v
+		})
+
+		testutil.EnsureError(t, step.Skip(pipeline.ConditionOpts{State: s}), pipeline.ErrorStepSkipped)
+		if err := s.SetBool(arg, true); err != nil {
+			t.Fatal(err)
+		}
+		testutil.EnsureError(t, step.Skip(pipeline.ConditionOpts{State: s}), nil)
+	})
+
+	t.Run("Steps should be skipped if none of their events match", func(t *testing.T) {
+		s := newState(t)
+		step := pipeline.NamedStep("step", pipeline.DefaultAction).WhenEvent(pipeline.GitCommitEvent(pipeline.GitCommitFilters{
+			Branch: pipeline.StringFilter("main"),
+		}))
+
+		// If the event is not known, then it was already checked by whatever ran the step.
+		testutil.EnsureError(t, step.Skip(pipeline.ConditionOpts{State: s}), nil)
+		testutil.EnsureError(t, step.Skip(pipeline.ConditionOpts{State: s, Event: "git-tag"}), pipeline.ErrorStepSkipped)
+
+		if err := s.SetString(pipeline.ArgumentBranch, "feature"); err != nil {
+			t.Fatal(err)
+		}
+		testutil.EnsureError(t, step.Skip(pipeline.ConditionOpts{State: s, Event: "git-commit"}), pipeline.ErrorStepSkipped)
+
+		if err := s.SetString(pipeline.ArgumentBranch, "main"); err != nil {
+			t.Fatal(err)
+		}
+		testutil.EnsureError(t, step.Skip(pipeline.ConditionOpts{State: s, Event: "git-commit"}), nil)
+	})
+}
@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrorStepTimeout = errors.New("step did not complete before its timeout")
+)
+
+// Retry defines how many times the action of a step is retried if it returns an error.
+type Retry struct {
+	// Retries is the number of times the action is ran again after the first attempt fails.
+	Retries int
+	// Backoff is how long to wait before the first retry. The wait doubles before every retry after that.
+	Backoff time.Duration
+}
+
+// WithTimeout sets how long the action of the step can run before it is cancelled and the step fails with ErrorStepTimeout.
+// When the step is retried, the timeout applies to every attempt.
+// A timeout of 0 removes the timeout, including the pipeline's default timeout.
+func (s Step) WithTimeout(d time.Duration) Step {
+	s.Timeout = d
+	s.timeoutSet = true
+	return s
+}
+
+// WithRetry runs the action of the step again, up to 'n' times, if it returns an error.
+// The client waits for 'backoff' before the first retry, and doubles the wait before every retry after that.
+// Setting 'n' to 0 disables retries, including the pipeline's default retries.
+func (s Step) WithRetry(n int, backoff time.Duration) Step {
+	s.Retry = Retry{
+		Retries: n,
+		Backoff: backoff,
+	}
+	s.retrySet = true
+	return s
+}
+
+// HasTimeout returns true if the step sets its own timeout, either with WithTimeout or by setting the Timeout field.
+// Default timeouts are only applied to steps that do not.
+func (s Step) HasTimeout() bool {
+	return s.timeoutSet || s.Timeout != 0
+}
+
+// HasRetry returns true if the step sets its own retries, either with WithRetry or by setting the Retry field.
+// Default retries are only applied to steps that do not.
+func (s Step) HasRetry() bool {
+	return s.retrySet || s.Retry != (Retry{})
+}
+
+// MaxDuration returns the longest time that the step can take, including every retry and the time waited between them.
+// If the step has no timeout, then 0 is returned, as there is no limit to how long it can take.
+func (s Step) MaxDuration() time.Duration {
+	if s.Timeout == 0 {
+		return 0
+	}
+
+	var (
+		d       = s.Timeout
+		backoff = s.Retry.Backoff
+	)
+
+	for i := 0; i < s.Retry.Retries; i++ {
+		d += backoff + s.Timeout
+		backoff *= 2
+	}
+
+	return d
+}
+
+// MaxDuration returns the longest time that the steps in the pipeline can take, which is the sum of the longest step in each list of steps.
+// Background steps are not included, as they are stopped when the pipeline is done.
+// If a step in the pipeline has no timeout, then 0 is returned, as there is no limit to how long the pipeline can take.
+func (p Pipeline) MaxDuration() time.Duration {
+	var d time.Duration
+	for _, node := range p.Graph.Nodes {
+		if node.Value.Type == StepTypeBackground {
+			continue
+		}
+
+		var longest time.Duration
+		for _, step := range node.Value.Steps {
+			if step.IsBackground() {
+				continue
+			}
+
+			max := step.MaxDuration()
+			if max == 0 {
+				return 0
+			}
+
+			if max > longest {
+				longest = max
+			}
+		}
+
+		d += longest
+	}
+
+	return d
+}
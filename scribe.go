@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/grafana/scribe/args"
 	"github.com/grafana/scribe/cmdutil"
@@ -41,6 +42,33 @@ type Scribe struct {
 
 	prev          []pipeline.StepList
 	prevPipelines []pipeline.Pipeline
+
+	// defaults are the timeout and retries that are used by steps that do not set their own.
+	defaults stepDefaults
+}
+
+// stepDefaults are the timeout and retries that are set with DefaultTimeout and DefaultRetry.
+type stepDefaults struct {
+	timeout time.Duration
+	retry   pipeline.Retry
+}
+
+// apply sets the default timeout and retries on the step if it does not set its own.
+// Background steps run until the pipeline is done, so the defaults are not applied to them.
+func (d stepDefaults) apply(step pipeline.Step) pipeline.Step {
+	if step.IsBackground() {
+		return step
+	}
+
+	if !step.HasTimeout() {
+		step.Timeout = d.timeout
+	}
+
+	if !step.HasRetry() {
+		step.Retry = d.retry
+	}
+
+	return step
 }
 
 // Pipeline returns the current Pipeline ID used in the collection.
@@ -65,6 +93,21 @@ func (s *Scribe) When(events ...pipeline.Event) {
 	}
 }
 
+// DefaultTimeout sets the timeout of every step that is added to the pipeline after it, unless the step sets its own with Step.WithTimeout, even if it is 0.
+// Sub-pipelines use the default of the pipeline that they are created in.
+func (s *Scribe) DefaultTimeout(d time.Duration) {
+	s.defaults.timeout = d
+}
+
+// DefaultRetry sets the retries of every step that is added to the pipeline after it, unless the step sets its own with Step.WithRetry, even if it is 0.
+// Sub-pipelines use the default of the pipeline that they are created in.
+func (s *Scribe) DefaultRetry(n int, backoff time.Duration) {
+	s.defaults.retry = pipeline.Retry{
+		Retries: n,
+		Backoff: backoff,
+	}
+}
+
 // Background allows users to define steps that run in the background. In some environments this is referred to as a "Service" or "Background service".
 // In many scenarios, users would like to simply use a docker image with the default command. In order to accomplish that, simply provide a step without an action.
 func (s *Scribe) Background(steps ...pipeline.Step) {
@@ -143,8 +186,72 @@ func (s *Scribe) parallelSteps(steps ...pipeline.Step) error {
 	return nil
 }
 
+// Auto adds the steps to the pipeline and orders them using the arguments that they require and provide, and the steps that they run after with Step.After.
+// Every step runs after the steps that provide its arguments, and steps that do not depend on each other run in parallel.
+// Like Run and Parallel, the steps run after the steps that were added before them, and steps that are added afterwards run after all of them.
+func (s *Scribe) Auto(steps ...pipeline.Step) {
+	s.Log.Debugf("Adding '%d' steps ordered by their arguments: %+v", len(steps), pipeline.StepNames(steps))
+	steps = s.setup(steps...)
+
+	if err := s.autoSteps(steps...); err != nil {
+		s.Log.Fatalln(err)
+	}
+}
+
+func (s *Scribe) autoSteps(steps ...pipeline.Step) error {
+	if err := s.validateSteps(steps...); err != nil {
+		return err
+	}
+
+	order, deps, err := pipeline.SortSteps(steps)
+	if err != nil {
+		return err
+	}
+
+	var (
+		lists = make([]pipeline.StepList, len(steps))
+		// last are the steps that no other step in this call runs after.
+		last = make([]bool, len(steps))
+	)
+
+	for _, i := range order {
+		list := pipeline.NewStepList(s.n.Next(), steps[i])
+		list.Dependencies = s.prev
+		if len(deps[i]) != 0 {
+			list.Dependencies = make([]pipeline.StepList, len(deps[i]))
+			for j, d := range deps[i] {
+				list.Dependencies[j] = lists[d]
+			}
+		}
+
+		if err := s.Collection.AddSteps(s.pipeline, list); err != nil {
+			return fmt.Errorf("Auto: error adding step '%d' to collection. error: %w", list.ID, err)
+		}
+
+		lists[i] = list
+		last[i] = true
+		for _, d := range deps[i] {
+			last[d] = false
+		}
+	}
+
+	prev := []pipeline.StepList{}
+	for _, i := range order {
+		if last[i] {
+			prev = append(prev, lists[i])
+		}
+	}
+
+	s.prev = prev
+
+	return nil
+}
+
+// Cache wraps the action so that it is skipped when the step's cache key matches a previous run.
+// When the step is skipped, the outputs defined in the Cacher are restored from the cache instead. See pipeline.CacheAction for more information.
+// The cache is stored at the location provided by the `-cache` argument.
 func (s *Scribe) Cache(action pipeline.Action, c pipeline.Cacher) pipeline.Action {
-	return action
+	return pipeline.CacheAction(action, c)
 }
 
 func (s *Scribe) setup(steps ...pipeline.Step) []pipeline.Step {
@@ -156,6 +263,8 @@ func (s *Scribe) setup(steps ...pipeline.Step) []pipeline.Step {
 			steps[i] = step.WithImage(image)
 		}
 
+		steps[i] = s.defaults.apply(steps[i])
+
 		// Set a serial / unique identifier for this step so that we can reference it using the '-step' argument consistently.
 		steps[i].ID = s.n.Next()
 	}
@@ -209,7 +318,7 @@ func (s *Scribe) Execute(ctx context.Context, collection *pipeline.Collection) e
 type SubFunc func(*Scribe)
 
 // This function adds a single sub-pipeline to the collection
-func (s *Scribe) subPipeline(sub *Scribe) error {
+func (s *Scribe) subPipeline(sub *Scribe, detached bool) error {
 	node, err := sub.Collection.Graph.Node(DefaultPipelineID)
 	if err != nil {
 		return fmt.Errorf("Failed to retrieve populated subpipeline: %w", err)
@@ -217,6 +326,7 @@ func (s *Scribe) subPipeline(sub *Scribe) error {
 
 	p := node.Value
 	p.Type = pipeline.PipelineTypeSub
+	p.Detached = detached
 	p.ID = s.n.Next()
 
 	if err := s.Collection.AddPipelines(p); err != nil {
@@ -228,16 +338,27 @@ func (s *Scribe) subPipeline(sub *Scribe) error {
 
 // Sub creates a sub-pipeline. The sub-pipeline is equivalent to creating a new coroutine made of multiple steps.
 // This sub-pipeline will run concurrently with the rest of the pipeline at the time of definition.
+// The pipeline does not finish until the sub-pipeline does, and if the sub-pipeline fails, then the pipeline fails too.
 // Under the hood, the Scribe client creates a new Scribe object with a clean Collection,
 // then calles the SubFunc (sf) with the new Scribe object. The collection is then populated by the SubFunc, and then appended to the existing collection.
 func (s *Scribe) Sub(sf SubFunc) {
+	s.sub(sf, false)
+}
+
+// SubDetached creates a sub-pipeline like Sub, but the pipeline does not wait for it to finish.
+// If the sub-pipeline fails, then the error is logged, but the pipeline does not fail. If it is still running once the rest of the pipeline is done, then it is stopped.
+func (s *Scribe) SubDetached(sf SubFunc) {
+	s.sub(sf, true)
+}
+
+func (s *Scribe) sub(sf SubFunc, detached bool) {
 	sub := s.newSub()
 
 	s.Log.Debugf("Populating sub-pipeline in call to Sub")
 	sf(sub)
 	s.Log.Debugf("Populated sub-pipeline with '%d' nodes and '%d' edges", len(sub.Collection.Graph.Nodes), len(sub.Collection.Graph.Edges))
 
-	if err := s.subPipeline(sub); err != nil {
+	if err := s.subPipeline(sub, detached); err != nil {
 		s.Log.WithError(err).Fatalln("failed to add sub-pipeline")
 	}
 }
@@ -257,6 +378,7 @@ func (s *Scribe) newSub() *Scribe {
 		n:          s.n,
 		Collection: collection,
 		pipeline:   DefaultPipelineID,
+		defaults:   s.defaults,
 	}
 }
 
@@ -300,6 +422,14 @@ func parseOpts() (clients.CommonOpts, error) {
 		return clients.CommonOpts{}, err
 	}
 
+	// Secrets that are read from or written to the state are removed from every log message, including the output of steps.
+	logger.AddHook(s.Redactor)
+
+	c, err := GetCache(pargs.Cache)
+	if err != nil {
+		return clients.CommonOpts{}, err
+	}
+
 	return clients.CommonOpts{
 		Version: pargs.Version,
 		Output:  os.Stdout,
@@ -307,6 +437,7 @@ func parseOpts() (clients.CommonOpts, error) {
 		Log:     logger,
 		Tracer:  tracer,
 		State:   s,
+		Cache:   c,
 	}, nil
 }
 
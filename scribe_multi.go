@@ -3,6 +3,7 @@ package scribe
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/grafana/scribe/args"
 	"github.com/grafana/scribe/pipeline"
@@ -23,6 +24,9 @@ type ScribeMulti struct {
 	pipeline int64
 
 	prev []pipeline.Pipeline
+
+	// defaults are the timeout and retries that are used by steps that do not set their own.
+	defaults stepDefaults
 }
 
 func (s *ScribeMulti) serial() int64 {
@@ -74,7 +78,7 @@ func (s *ScribeMulti) Parallel(steps ...pipeline.Pipeline) {
 	}
 }
 
-func (s *ScribeMulti) subMulti(sub *ScribeMulti) error {
+func (s *ScribeMulti) subMulti(sub *ScribeMulti, detached bool) error {
 	prev := s.prev
 
 	for i, v := range sub.Collection.Graph.Nodes {
@@ -83,6 +87,7 @@ func (s *ScribeMulti) subMulti(sub *ScribeMulti) error {
 		}
 
 		sub.Collection.Graph.Nodes[i].Value.Type = pipeline.PipelineTypeSub
+		sub.Collection.Graph.Nodes[i].Value.Detached = detached
 
 		if len(v.Value.Dependencies) == 0 {
 			sub.Collection.Graph.Nodes[i].Value.Dependencies = prev
@@ -112,16 +117,44 @@ func (s *ScribeMulti) newSub() *ScribeMulti {
 		n:          s.n,
 		Collection: collection,
 		pipeline:   DefaultPipelineID,
+		defaults:   s.defaults,
+	}
+}
+
+// DefaultTimeout sets the timeout of every step in the pipelines that are created after it, unless the step sets its own with Step.WithTimeout.
+// It can be overridden in each pipeline with (*Scribe).DefaultTimeout.
+func (s *ScribeMulti) DefaultTimeout(d time.Duration) {
+	s.defaults.timeout = d
+}
+
+// DefaultRetry sets the retries of every step in the pipelines that are created after it, unless the step sets its own with Step.WithRetry.
+// It can be overridden in each pipeline with (*Scribe).DefaultRetry.
+func (s *ScribeMulti) DefaultRetry(n int, backoff time.Duration) {
+	s.defaults.retry = pipeline.Retry{
+		Retries: n,
+		Backoff: backoff,
 	}
 }
 
 type MultiSubFunc func(*ScribeMulti)
 
+// Sub creates sub-pipelines that run concurrently with the pipelines that are added after them.
+// The pipeline does not finish until the sub-pipelines do, and if one of them fails, then the pipeline fails too.
 func (s *ScribeMulti) Sub(sf MultiSubFunc) {
+	s.sub(sf, false)
+}
+
+// SubDetached creates sub-pipelines like Sub, but the pipeline does not wait for them to finish.
+// If one of them fails, then the error is logged, but the pipeline does not fail. If they are still running once the rest of the pipeline is done, then they are stopped.
+func (s *ScribeMulti) SubDetached(sf MultiSubFunc) {
+	s.sub(sf, true)
+}
+
+func (s *ScribeMulti) sub(sf MultiSubFunc, detached bool) {
 	sub := s.newSub()
 	sf(sub)
 
-	if err := s.subMulti(sub); err != nil {
+	if err := s.subMulti(sub, detached); err != nil {
 		s.Log.WithError(err).Fatalln("failed to add sub-pipeline")
 	}
 }
@@ -250,6 +283,7 @@ func (s *ScribeMulti) newMulti(name string) (*Scribe, error) {
 		n:          s.n,
 		Collection: collection,
 		pipeline:   DefaultPipelineID,
+		defaults:   s.defaults,
 	}
 
 	return sw, nil
@@ -2,8 +2,10 @@ package scribe_test
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/grafana/scribe"
 	"github.com/grafana/scribe/args"
@@ -14,6 +16,7 @@ import (
 	"github.com/grafana/scribe/pipeline/clients/drone"
 	"github.com/grafana/scribe/pipeline/dag"
 	"github.com/grafana/scribe/plog"
+	"github.com/grafana/scribe/state"
 	"github.com/sirupsen/logrus"
 )
 
@@ -161,6 +164,99 @@ func TestScribeRun(t *testing.T) {
 	})
 }
 
+func TestScribeAuto(t *testing.T) {
+	var (
+		x = state.NewStringArgument("x")
+		y = state.NewStringArgument("y")
+	)
+
+	t.Run("Steps should run after the steps that provide their arguments", func(t *testing.T) {
+		client := scribe.NewWithClient(testOpts, newEnsurer())
+		client.Auto(
+			pipeline.NoOpStep.WithName("d").Requires(y),
+			pipeline.NoOpStep.WithName("b").Requires(x).Provides(y),
+			pipeline.NoOpStep.WithName("a").Provides(x),
+			pipeline.NoOpStep.WithName("c"),
+		)
+		client.Run(pipeline.NoOpStep.WithName("e"))
+
+		n, err := client.Collection.Graph.Node(scribe.DefaultPipelineID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 'a' (5) and 'c' (6) have no dependencies, 'b' (7) runs after 'a', and 'd' (8) runs after 'b'.
+		// 'e' (10) runs after the steps that no other step runs after.
+		dag.EnsureGraphEdges(t, map[int64][]int64{
+			0: {5, 6},
+			5: {7},
+			6: {10},
+			7: {8},
+			8: {10},
+		}, n.Value.Graph.Edges)
+	})
+
+	t.Run("Steps should run after the steps in Step.After", func(t *testing.T) {
+		client := scribe.NewWithClient(testOpts, newEnsurer())
+		a := pipeline.NoOpStep.WithName("a")
+		client.Auto(pipeline.NoOpStep.WithName("b").After(a), a)
+
+		n, err := client.Collection.Graph.Node(scribe.DefaultPipelineID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dag.EnsureGraphEdges(t, map[int64][]int64{
+			0: {3},
+			3: {4},
+		}, n.Value.Graph.Edges)
+	})
+
+	t.Run("Steps that depend on each other in a cycle should return an error", func(t *testing.T) {
+		_, _, err := pipeline.SortSteps([]pipeline.Step{
+			pipeline.NoOpStep.WithName("a").Requires(x).Provides(y),
+			pipeline.NoOpStep.WithName("b").Requires(y).Provides(x),
+		})
+		if !errors.Is(err, dag.ErrorCycle) {
+			t.Fatalf("Expected error '%v' but got '%v'", dag.ErrorCycle, err)
+		}
+	})
+}
+
+func TestScribeDefaults(t *testing.T) {
+	client := scribe.NewWithClient(testOpts, newEnsurer())
+	client.DefaultTimeout(time.Minute)
+	client.DefaultRetry(1, time.Second)
+	client.Background(pipeline.NoOpStep.WithName("service"))
+	client.Run(
+		pipeline.NoOpStep.WithName("a"),
+		pipeline.NoOpStep.WithName("b").WithTimeout(time.Hour).WithRetry(2, time.Minute),
+		pipeline.NoOpStep.WithName("c").WithTimeout(0).WithRetry(0, 0),
+	)
+
+	n, err := client.Collection.Graph.Node(scribe.DefaultPipelineID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]pipeline.Step{
+		"service": {},
+		"a":       pipeline.Step{}.WithTimeout(time.Minute).WithRetry(1, time.Second),
+		"b":       pipeline.Step{}.WithTimeout(time.Hour).WithRetry(2, time.Minute),
+		// Steps can opt out of the defaults by setting them to 0.
+		"c": {},
+	}
+
+	for _, node := range n.Value.Graph.Nodes {
+		for _, step := range node.Value.Steps {
+			e := expected[step.Name]
+			if step.Timeout != e.Timeout || step.Retry != e.Retry {
+				t.Errorf("Unexpected timeout or retry for step '%s'. Expected '%s', '%+v' but got '%s', '%+v'", step.Name, e.Timeout, e.Retry, step.Timeout, step.Retry)
+			}
+		}
+	}
+}
+
 func TestBasicPipeline(t *testing.T) {
 	ensurer := newEnsurer([]string{"step 1"}, []string{"step 2", "step 3", "step 4"}, []string{"step 5"})
 
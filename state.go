@@ -1,10 +1,12 @@
 package scribe
 
 import (
+	"bytes"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/grafana/scribe/args"
 	"github.com/grafana/scribe/state"
@@ -12,20 +14,178 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func newFilesystemState(u *url.URL) (state.StateHandler, error) {
-	path := u.Path
-	if info, err := os.Stat(path); err == nil {
-		if info.IsDir() {
-			path = filepath.Join(path, fmt.Sprintf("%s.json", stringutil.Random(8)))
+// newFilesystemState creates a state that is stored in a JSON file, namespaced by the build ID so that builds which run at the same time on one machine don't share values.
+// If the URL refers to a directory, like 'file:///var/scribe/state/', then the state file is '/var/scribe/state/{build-id}.json'.
+// Otherwise, the state file is placed in a folder named after the build ID, so 'file:///var/scribe-state/state.json' becomes '/var/scribe-state/{build-id}/state.json'.
+// The build ID is only used if it was provided with '-build-id', as the default is random and would create a new state on every run.
+// Without it, the state file is not namespaced, and a random name is used for directories.
+func newFilesystemState(u *url.URL, pargs *args.PipelineArgs) (state.StateHandler, error) {
+	var (
+		path    = u.Path
+		buildID string
+	)
+
+	if pargs.BuildIDProvided {
+		buildID = stringutil.Slugify(pargs.BuildID)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		name := buildID
+		if name == "" {
+			name = stringutil.Random(8)
 		}
+
+		return state.NewFilesystemState(filepath.Join(path, fmt.Sprintf("%s.json", name)))
+	}
+
+	if buildID != "" {
+		path = filepath.Join(filepath.Dir(path), buildID, filepath.Base(path))
 	}
 
 	return state.NewFilesystemState(path)
 }
 
-var states = map[string]func(*url.URL) (state.StateHandler, error){
-	"file": newFilesystemState,
-	"fs":   newFilesystemState,
+// firstEnv returns the value of the first environment variable in the list that is not empty.
+func firstEnv(names ...string) string {
+	for _, v := range names {
+		if value := os.Getenv(v); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// newS3State creates an S3 state from a URL like 's3://bucket-name/path?region=eu-west-1&endpoint=http://localhost:9000'.
+// The credentials, and the region and endpoint if they are not in the URL, are read from the standard AWS environment variables.
+func newS3State(u *url.URL, _ *args.PipelineArgs) (state.StateHandler, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("s3 state URL '%s' does not contain a bucket", u.String())
+	}
+
+	var (
+		query    = u.Query()
+		region   = query.Get("region")
+		endpoint = query.Get("endpoint")
+	)
+
+	if region == "" {
+		region = firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	}
+
+	if endpoint == "" {
+		endpoint = firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL")
+	}
+
+	return state.NewS3State(state.S3Options{
+		Bucket:          u.Host,
+		Region:          region,
+		Endpoint:        endpoint,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}, u.Path), nil
+}
+
+// newGCSState creates a Google Cloud Storage state from a URL like 'gcs://bucket-name/path?endpoint=http://localhost:4443'.
+// If the endpoint is not in the URL, then the 'STORAGE_EMULATOR_HOST' environment variable is used, which is also used by the Google Cloud SDKs to connect to a local emulator.
+// An access token can be provided using the 'GOOGLE_OAUTH_ACCESS_TOKEN' environment variable; otherwise the token of the default service account is used.
+func newGCSState(u *url.URL, _ *args.PipelineArgs) (state.StateHandler, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("gcs state URL '%s' does not contain a bucket", u.String())
+	}
+
+	endpoint := u.Query().Get("endpoint")
+	if endpoint == "" {
+		endpoint = os.Getenv("STORAGE_EMULATOR_HOST")
+		if endpoint != "" && !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	}
+
+	return state.NewGCSState(state.GCSOptions{
+		Bucket:   u.Host,
+		Endpoint: endpoint,
+		Token:    os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+	}, u.Path), nil
+}
+
+// newHTTPState creates a state that connects to a state server, like one started with 'scribe state serve', from a URL like 'http://localhost:8080'.
+func newHTTPState(u *url.URL, _ *args.PipelineArgs) (state.StateHandler, error) {
+	return state.NewHTTPState(u.String()), nil
+}
+
+var states = map[string]func(*url.URL, *args.PipelineArgs) (state.StateHandler, error){
+	"file":  newFilesystemState,
+	"fs":    newFilesystemState,
+	"s3":    newS3State,
+	"gcs":   newGCSState,
+	"gs":    newGCSState,
+	"http":  newHTTPState,
+	"https": newHTTPState,
+}
+
+// fallbackReaders returns the readers that values are read from when they are not in the state, in order of precedence:
+//  1. The '-arg' flags.
+//  2. Environment variables, like 'SCRIBE_ARG_GIT_BRANCH', if the '-arg-env-prefix' flag is not empty or '-arg-env' flags are provided.
+//  3. The file provided with the '-arg-file' flag.
+//
+// The user is prompted via stdin for values that are not found in any of these readers.
+func fallbackReaders(log logrus.FieldLogger, pargs *args.PipelineArgs) ([]state.StateReader, error) {
+	fallback := []state.StateReader{
+		state.StateReaderWithLogs(log.WithField("state", "arguments"), state.NewArgMapReader(pargs.ArgMap)),
+	}
+
+	if pargs.ArgEnvPrefix != "" || len(pargs.ArgEnv) != 0 {
+		fallback = append(fallback, state.StateReaderWithLogs(log.WithField("state", "env"), state.NewEnvReader(pargs.ArgEnvPrefix, pargs.ArgEnv)))
+	}
+
+	if pargs.ArgFile != "" {
+		var (
+			reader state.StateReader
+			err    error
+		)
+
+		switch filepath.Ext(pargs.ArgFile) {
+		case ".yml", ".yaml":
+			reader, err = state.NewArgMapFileReader(pargs.ArgFile)
+		default:
+			reader, err = state.NewEnvFileReader(pargs.ArgFile, pargs.ArgEnvPrefix, pargs.ArgEnv)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		fallback = append(fallback, state.StateReaderWithLogs(log.WithField("state", "file"), reader))
+	}
+
+	return fallback, nil
+}
+
+// stateKey returns the key used to encrypt secrets in the state, which is read from the 'SCRIBE_STATE_KEY' environment variable or the file provided with the '-state-key-file' flag.
+// If neither is provided, then an empty key is returned and secrets are not encrypted.
+func stateKey(pargs *args.PipelineArgs) ([]byte, error) {
+	// The key is trimmed like the contents of the key file, as the Dagger client provides the file's contents to its containers in this variable.
+	if key := strings.TrimSpace(os.Getenv("SCRIBE_STATE_KEY")); key != "" {
+		return []byte(key), nil
+	}
+
+	if pargs.StateKeyFile == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(pargs.StateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading state key file: %w", err)
+	}
+
+	key := bytes.TrimSpace(b)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("state key file '%s' is empty", pargs.StateKeyFile)
+	}
+
+	return key, nil
 }
 
 func GetState(val string, log logrus.FieldLogger, pargs *args.PipelineArgs) (*state.State, error) {
@@ -34,8 +194,9 @@ func GetState(val string, log logrus.FieldLogger, pargs *args.PipelineArgs) (*st
 		return nil, err
 	}
 
-	fallback := []state.StateReader{
-		state.StateReaderWithLogs(log.WithField("state", "arguments"), state.NewArgMapReader(pargs.ArgMap)),
+	fallback, err := fallbackReaders(log, pargs)
+	if err != nil {
+		return nil, err
 	}
 
 	if pargs.CanStdinPrompt {
@@ -43,15 +204,30 @@ func GetState(val string, log logrus.FieldLogger, pargs *args.PipelineArgs) (*st
 	}
 
 	if v, ok := states[u.Scheme]; ok {
-		handler, err := v(u)
+		handler, err := v(u, pargs)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := stateKey(pargs)
 		if err != nil {
 			return nil, err
 		}
 
+		if key != nil {
+			handler, err = state.NewEncryptedStateHandler(handler, key)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			log.Debugln("No state key was provided; secrets are stored in the state in plaintext")
+		}
+
 		return &state.State{
 			Handler:  state.StateHandlerWithLogs(log.WithField("state", u.Scheme), handler),
 			Fallback: fallback,
 			Log:      log,
+			Redactor: state.NewRedactor(),
 		}, nil
 	}
 
@@ -1,5 +1,7 @@
 package state
 
+import "fmt"
+
 type ArgumentType int
 
 const (
@@ -15,15 +17,32 @@ const (
 	// Filesystems and directories used with this argument should always exist on every machine. This basically means that they should be available within the source tree.
 	// If this argument type is used for directories outside of the source tree, then expect divergeant behavior between operating systems.
 	ArgumentTypeUnpackagedFS
+	// An ArgumentTypeStringSlice is a list of strings, like a list of built artifacts.
+	ArgumentTypeStringSlice
+	// An ArgumentTypeStringMap is a map of strings, like a version matrix.
+	ArgumentTypeStringMap
+	// An ArgumentTypeJSON is any value that can be encoded as JSON, like a struct. Use the GetJSON and SetJSON functions to read and write them.
+	ArgumentTypeJSON
 )
 
-var argumentTypeStr = []string{"string", "int", "float", "bool", "secret", "file", "directory", "unpackaged-directory"}
+var argumentTypeStr = []string{"string", "int", "float", "bool", "secret", "file", "directory", "unpackaged-directory", "string-slice", "string-map", "json"}
 
 func (a ArgumentType) String() string {
 	i := int(a)
 	return argumentTypeStr[i]
 }
 
+// ParseArgumentType returns the ArgumentType whose String value is 's'.
+func ParseArgumentType(s string) (ArgumentType, error) {
+	for i, v := range argumentTypeStr {
+		if v == s {
+			return ArgumentType(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("argument type '%s' not recognized", s)
+}
+
 func ArgumentTypesEqual(arg Argument, argTypes ...ArgumentType) bool {
 	for _, v := range argTypes {
 		if arg.Type == v {
@@ -100,3 +119,24 @@ func NewSecretArgument(key string) Argument {
 		Key:  key,
 	}
 }
+
+func NewStringSliceArgument(key string) Argument {
+	return Argument{
+		Type: ArgumentTypeStringSlice,
+		Key:  key,
+	}
+}
+
+func NewStringMapArgument(key string) Argument {
+	return Argument{
+		Type: ArgumentTypeStringMap,
+		Key:  key,
+	}
+}
+
+func NewJSONArgument(key string) Argument {
+	return Argument{
+		Type: ArgumentTypeJSON,
+		Key:  key,
+	}
+}
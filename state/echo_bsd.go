@@ -0,0 +1,15 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package state
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// disableEcho stops the terminal from echoing the characters that are typed into it.
+// If the file is not a terminal, then false is returned. Otherwise, the returned function restores the terminal.
+func disableEcho(f *os.File) (func(), bool) {
+	return disableEchoIoctl(int(f.Fd()), unix.TIOCGETA, unix.TIOCSETA)
+}
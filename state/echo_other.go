@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package state
+
+import "os"
+
+// disableEcho is not supported on this operating system, so values typed into the terminal are always echoed.
+func disableEcho(f *os.File) (func(), bool) {
+	return nil, false
+}
@@ -0,0 +1,22 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package state
+
+import "golang.org/x/sys/unix"
+
+func disableEchoIoctl(fd int, get, set uint) (func(), bool) {
+	termios, err := unix.IoctlGetTermios(fd, get)
+	if err != nil {
+		return nil, false
+	}
+
+	restore := *termios
+	termios.Lflag &^= unix.ECHO
+	if err := unix.IoctlSetTermios(fd, set, termios); err != nil {
+		return nil, false
+	}
+
+	return func() {
+		unix.IoctlSetTermios(fd, set, &restore)
+	}, true
+}
@@ -0,0 +1,28 @@
+package state
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// disableEcho stops the console from echoing the characters that are typed into it.
+// If the file is not a console, then false is returned. Otherwise, the returned function restores the console.
+func disableEcho(f *os.File) (func(), bool) {
+	var (
+		handle = windows.Handle(f.Fd())
+		mode   uint32
+	)
+
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return nil, false
+	}
+
+	if err := windows.SetConsoleMode(handle, mode&^windows.ENABLE_ECHO_INPUT); err != nil {
+		return nil, false
+	}
+
+	return func() {
+		windows.SetConsoleMode(handle, mode)
+	}, true
+}
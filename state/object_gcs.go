@@ -0,0 +1,180 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gcsMetadataTokenURL is where the access token of the default service account is available when running on Google Cloud, like in GKE.
+const gcsMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCSOptions configure the connection to Google Cloud Storage.
+type GCSOptions struct {
+	Bucket string
+	// Endpoint is the URL of the service. Defaults to 'https://storage.googleapis.com'.
+	// A different endpoint, like the one of a local fake GCS server, can be used for testing.
+	Endpoint string
+
+	// Token is an OAuth2 access token that is used to authorize requests.
+	// If it is empty and the endpoint is the default one, then the token of the default service account is requested from the metadata server.
+	// If it is empty and a different endpoint is used, then requests are not authorized.
+	Token string
+
+	// Client is the HTTP client used to make requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// GCSStore is an ObjectStore that stores objects in a Google Cloud Storage bucket using the JSON API.
+// The version of an object is its generation.
+type GCSStore struct {
+	opts     GCSOptions
+	metadata bool
+
+	mtx     *sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func NewGCSStore(opts GCSOptions) *GCSStore {
+	metadata := opts.Endpoint == "" && opts.Token == ""
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://storage.googleapis.com"
+	}
+
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	opts.Endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+	return &GCSStore{
+		opts:     opts,
+		metadata: metadata,
+		mtx:      &sync.Mutex{},
+	}
+}
+
+// NewGCSState creates an ObjectState that stores state in the GCS bucket, with every object name prefixed by 'prefix'.
+func NewGCSState(opts GCSOptions, prefix string) *ObjectState {
+	return NewObjectState(NewGCSStore(opts), prefix)
+}
+
+// accessToken returns the token used to authorize requests. Tokens from the metadata server are cached until shortly before they expire.
+func (g *GCSStore) accessToken(ctx context.Context) (string, error) {
+	if !g.metadata {
+		return g.opts.Token, nil
+	}
+
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.token != "" && time.Now().Before(g.expires) {
+		return g.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcsMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Metadata-Flavor", "Google")
+	res, err := g.opts.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting access token from the metadata server: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", responseError(res)
+	}
+
+	token := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{}
+
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	g.token = token.AccessToken
+	g.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return g.token, nil
+}
+
+func (g *GCSStore) do(req *http.Request) (*http.Response, error) {
+	token, err := g.accessToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return g.opts.Client.Do(req)
+}
+
+func (g *GCSStore) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", g.opts.Endpoint, url.PathEscape(g.opts.Bucket), url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	res, err := g.do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, "", responseError(res)
+	}
+
+	return res.Body, res.Header.Get("X-Goog-Generation"), nil
+}
+
+// Put uploads the object. Preconditions are sent using the 'ifGenerationMatch' parameter, where a generation of '0' means that the object must not exist.
+func (g *GCSStore) Put(ctx context.Context, key string, r io.Reader, size int64, pre *Precondition) error {
+	query := url.Values{}
+	query.Set("uploadType", "media")
+	query.Set("name", key)
+	if pre != nil {
+		generation := pre.Version
+		if generation == "" {
+			generation = "0"
+		}
+		query.Set("ifGenerationMatch", generation)
+	}
+
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", g.opts.Endpoint, url.PathEscape(g.opts.Bucket), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, r)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	res, err := g.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package state_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/scribe/state"
+)
+
+type gcsObject struct {
+	data       []byte
+	generation int64
+}
+
+// fakeGCS is a minimal stand-in for a fake GCS server that stores objects in memory.
+// It supports downloading objects and simple uploads with the 'ifGenerationMatch' precondition.
+type fakeGCS struct {
+	mtx        sync.Mutex
+	objects    map[string]gcsObject
+	generation int64
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/bucket/o/"):
+		v, ok := f.objects[strings.TrimPrefix(r.URL.Path, "/storage/v1/b/bucket/o/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Goog-Generation", strconv.FormatInt(v.generation, 10))
+		w.Write(v.data)
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/bucket/o":
+		var (
+			name  = r.URL.Query().Get("name")
+			match = r.URL.Query().Get("ifGenerationMatch")
+		)
+
+		if match != "" && match != strconv.FormatInt(f.objects[name].generation, 10) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		f.generation++
+		f.objects[name] = gcsObject{
+			data:       body,
+			generation: f.generation,
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeGCS) keys() []string {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	keys := []string{}
+	for k := range f.objects {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func TestGCSState(t *testing.T) {
+	f := &fakeGCS{objects: map[string]gcsObject{}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	testObjectState(t, func(t *testing.T) *state.ObjectState {
+		return state.NewGCSState(state.GCSOptions{
+			Bucket:   "bucket",
+			Endpoint: srv.URL,
+			Token:    "test-token",
+		}, "builds/1")
+	}, f.keys)
+}
@@ -0,0 +1,242 @@
+package state
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Options configure the connection to an S3-compatible object storage service, like AWS S3 or MinIO.
+type S3Options struct {
+	Bucket string
+	// Region is the region of the bucket. Defaults to 'us-east-1'.
+	Region string
+	// Endpoint is the URL of the service, like 'http://localhost:9000'. Defaults to 'https://s3.{region}.amazonaws.com'.
+	// Objects are always addressed with a path ('{endpoint}/{bucket}/{key}'), which is supported by AWS and most S3-compatible services.
+	Endpoint string
+
+	// If AccessKeyID is empty, then requests are not signed.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Client is the HTTP client used to make requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// S3Store is an ObjectStore that stores objects in an S3 bucket.
+// Requests are signed with AWS Signature Version 4. The payload is not signed so that objects can be streamed.
+type S3Store struct {
+	opts S3Options
+}
+
+func NewS3Store(opts S3Options) *S3Store {
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+
+	if opts.Endpoint == "" {
+		opts.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", opts.Region)
+	}
+
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	opts.Endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+	return &S3Store{
+		opts: opts,
+	}
+}
+
+// NewS3State creates an ObjectState that stores state in the S3 bucket, with every object key prefixed by 'prefix'.
+func NewS3State(opts S3Options, prefix string) *ObjectState {
+	return NewObjectState(NewS3Store(opts), prefix)
+}
+
+// awsEscape encodes the value using the URI encoding that is required when signing AWS requests.
+// If 'path' is true, then '/' is not encoded.
+func awsEscape(v string, path bool) string {
+	b := strings.Builder{}
+	for _, c := range []byte(v) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && path:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data string) string {
+	h := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(h[:])
+}
+
+// unsignedPayload is used instead of the SHA256 of the body so that the body does not have to be read before it's sent.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// sign adds the AWS Signature Version 4 'Authorization' header to the request.
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html.
+func (s *S3Store) sign(req *http.Request, now time.Time) {
+	var (
+		amzDate = now.UTC().Format("20060102T150405Z")
+		date    = now.UTC().Format("20060102")
+		scope   = fmt.Sprintf("%s/%s/s3/aws4_request", date, s.opts.Region)
+	)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	if s.opts.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.opts.SessionToken)
+	}
+
+	if s.opts.AccessKeyID == "" {
+		return
+	}
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": unsignedPayload,
+		"x-amz-date":           amzDate,
+	}
+	if s.opts.SessionToken != "" {
+		headers["x-amz-security-token"] = s.opts.SessionToken
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := strings.Builder{}
+	for _, k := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", k, headers[k])
+	}
+
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsEscape(req.URL.Path, true),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretAccessKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.opts.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func (s *S3Store) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u, err := url.Parse(s.opts.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setting RawPath ensures that the key is escaped the same way as it is when signing the request.
+	u.Path = path.Join("/", u.Path, s.opts.Bucket, key)
+	u.RawPath = awsEscape(u.Path, true)
+
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// responseError converts an unsuccessful response into an error.
+func responseError(res *http.Response) error {
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		return ErrorObjectNotFound
+	// S3 returns a 409 if a conditional write conflicts with another write that is in progress.
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return ErrorPreconditionFailed
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("request to object store failed with status '%s': %s", res.Status, strings.TrimSpace(string(body)))
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.sign(req, time.Now())
+	res, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, "", responseError(res)
+	}
+
+	return res.Body, res.Header.Get("ETag"), nil
+}
+
+// Put writes the object. Preconditions are sent using the 'If-Match' and 'If-None-Match' headers.
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, pre *Precondition) error {
+	req, err := s.request(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+
+	if pre != nil {
+		if pre.Version == "" {
+			req.Header.Set("If-None-Match", "*")
+		} else {
+			req.Header.Set("If-Match", pre.Version)
+		}
+	}
+
+	s.sign(req, time.Now())
+	res, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package state_test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/scribe/state"
+)
+
+// fakeS3 is a minimal stand-in for an S3-compatible service, like MinIO, that stores objects in memory.
+// It supports getting and putting objects with the 'If-Match' and 'If-None-Match' preconditions.
+type fakeS3 struct {
+	mtx     sync.Mutex
+	objects map[string][]byte
+}
+
+func etag(b []byte) string {
+	sum := md5.Sum(b)
+	return fmt.Sprintf("%q", hex.EncodeToString(sum[:]))
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=test-key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodGet:
+		v, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", etag(v))
+		w.Write(v)
+	case http.MethodPut:
+		v, exists := f.objects[key]
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || match != etag(v)) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		f.objects[key] = body
+		w.Header().Set("ETag", etag(body))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) keys() []string {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	keys := []string{}
+	for k := range f.objects {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func newS3State(t *testing.T, f *fakeS3) *state.ObjectState {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	return state.NewS3State(state.S3Options{
+		Bucket:          "bucket",
+		Endpoint:        srv.URL,
+		AccessKeyID:     "test-key",
+		SecretAccessKey: "test-secret",
+	}, "builds/1")
+}
+
+func TestS3State(t *testing.T) {
+	f := &fakeS3{objects: map[string][]byte{}}
+	testObjectState(t, func(t *testing.T) *state.ObjectState {
+		return newS3State(t, f)
+	}, f.keys)
+}
@@ -16,6 +16,8 @@ var (
 	ErrorNotFound   = errors.New("key not found in state")
 	ErrorKeyExists  = errors.New("key already exists in state")
 	ErrorReadOnly   = errors.New("state is read-only")
+	// ErrorCorruptState is returned when the stored state can not be decoded, for example if it was partially written.
+	ErrorCorruptState = errors.New("state is corrupt")
 )
 
 type StateReader interface {
@@ -48,6 +50,15 @@ type State struct {
 	Handler  StateHandler
 	Fallback []StateReader
 	Log      logrus.FieldLogger
+	// Redactor, if not nil, receives the value of every secret that is read from or written to the state so that it can be removed from logs.
+	Redactor *Redactor
+}
+
+// addSecret adds the value to the Redactor if the argument is a secret.
+func (s *State) addSecret(arg Argument, value string) {
+	if s.Redactor != nil && arg.Type == ArgumentTypeSecret {
+		s.Redactor.Add(value)
+	}
 }
 
 // Exists checks the state to see if an argument exists in it.
@@ -87,6 +98,12 @@ func (s *State) GetString(arg Argument) (string, error) {
 
 	value, err := s.Handler.GetString(arg)
 	if err == nil {
+		// An EncryptedStateHandler decrypts secrets before they are returned, so an encrypted secret means that the state has no key.
+		if arg.Type == ArgumentTypeSecret && IsEncrypted(value) {
+			return "", fmt.Errorf("%w: '%s'", ErrorStateKeyRequired, arg.Key)
+		}
+
+		s.addSecret(arg, value)
 		return value, nil
 	}
 
@@ -356,6 +373,7 @@ func (s *State) SetString(arg Argument, value string) error {
 		return fmt.Errorf("attempted to set string in state for wrong argument type '%s'", arg.Type)
 	}
 
+	s.addSecret(arg, value)
 	return s.Handler.SetString(arg, value)
 }
 
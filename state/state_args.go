@@ -1,11 +1,14 @@
 package state
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/fs"
 	"os"
 	"strconv"
 
 	"github.com/grafana/scribe/args"
+	"gopkg.in/yaml.v2"
 )
 
 // ArgMapReader attempts to read state values from the provided "ArgMap".
@@ -21,6 +24,78 @@ func NewArgMapReader(defaults args.ArgMap) *ArgMapReader {
 	}
 }
 
+// yamlScalar returns the string value of a string, number, or boolean from a YAML document.
+func yamlScalar(v any) (string, bool) {
+	switch v.(type) {
+	case string, int, int64, float64, bool:
+		return fmt.Sprint(v), true
+	}
+
+	return "", false
+}
+
+// yamlValue returns the string value of a value in a YAML argument file.
+// Lists and maps of scalar values are encoded as JSON, which is how string slice and string map arguments are stored in the state.
+func yamlValue(v any) (string, bool) {
+	if s, ok := yamlScalar(v); ok {
+		return s, true
+	}
+
+	switch value := v.(type) {
+	case []any:
+		list := make([]string, len(value))
+		for i, item := range value {
+			s, ok := yamlScalar(item)
+			if !ok {
+				return "", false
+			}
+			list[i] = s
+		}
+
+		b, err := json.Marshal(list)
+		return string(b), err == nil
+	case map[any]any:
+		m := make(map[string]string, len(value))
+		for k, item := range value {
+			s, ok := yamlScalar(item)
+			if !ok {
+				return "", false
+			}
+			m[fmt.Sprint(k)] = s
+		}
+
+		b, err := json.Marshal(m)
+		return string(b), err == nil
+	}
+
+	return "", false
+}
+
+// NewArgMapFileReader creates an ArgMapReader from a YAML file where every key is the key of an argument, like 'git-branch: main'.
+// Values can be strings, numbers, booleans, or lists and maps of them for string slice and string map arguments.
+func NewArgMapFileReader(path string) (*ArgMapReader, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("error reading argument file '%s': %w", path, err)
+	}
+
+	m := args.ArgMap{}
+	for k, v := range values {
+		s, ok := yamlValue(v)
+		if !ok {
+			return nil, fmt.Errorf("error reading argument file '%s': the value of '%s' is not a string, number, boolean, or a list or map of them", path, k)
+		}
+		m[k] = s
+	}
+
+	return NewArgMapReader(m), nil
+}
+
 func (s *ArgMapReader) GetString(arg Argument) (string, error) {
 	val, err := s.defaults.Get(arg.Key)
 	if err != nil {
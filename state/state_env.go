@@ -0,0 +1,198 @@
+package state
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EnvReader attempts to read state values from environment variables.
+// The name of the environment variable for an argument is the prefix followed by the argument's key in upper case, where every character that is not a letter or number is replaced with a '_'.
+// For example, with the prefix 'SCRIBE_ARG_', the value for the 'git-branch' argument is read from 'SCRIBE_ARG_GIT_BRANCH'.
+type EnvReader struct {
+	// Prefix is prepended to the name of every environment variable.
+	Prefix string
+	// Keys overrides the name of the environment variable for the arguments with these keys. The prefix is not added to these names.
+	// For example, '{"git-branch": "DRONE_BRANCH"}' reads the 'git-branch' argument from 'DRONE_BRANCH'.
+	Keys map[string]string
+
+	lookup func(string) (string, bool)
+}
+
+// NewEnvReader creates an EnvReader that reads from the environment of the current process.
+func NewEnvReader(prefix string, keys map[string]string) *EnvReader {
+	return &EnvReader{
+		Prefix: prefix,
+		Keys:   keys,
+		lookup: os.LookupEnv,
+	}
+}
+
+// NewEnvFileReader creates an EnvReader that reads from the variables in a '.env' file instead of the environment.
+// Every line of the file is a 'NAME=value' pair. Empty lines, comments that start with '#', and the 'export' keyword are ignored, and values can be quoted.
+func NewEnvFileReader(path, prefix string, keys map[string]string) (*EnvReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values, err := parseEnvFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading env file '%s': %w", path, err)
+	}
+
+	return &EnvReader{
+		Prefix: prefix,
+		Keys:   keys,
+		lookup: func(name string) (string, bool) {
+			v, ok := values[name]
+			return v, ok
+		},
+	}, nil
+}
+
+func parseEnvFile(r io.Reader) (map[string]string, error) {
+	var (
+		values  = map[string]string{}
+		scanner = bufio.NewScanner(r)
+		line    = 0
+	)
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimPrefix(text, "export ")
+		p := strings.SplitN(text, "=", 2)
+		if len(p) != 2 {
+			return nil, fmt.Errorf("line %d is not a 'NAME=value' pair", line)
+		}
+
+		var (
+			name  = strings.TrimSpace(p[0])
+			value = strings.TrimSpace(p[1])
+		)
+
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			if value[0] == '"' {
+				v, err := strconv.Unquote(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				value = v
+			} else {
+				value = value[1 : len(value)-1]
+			}
+		}
+
+		values[name] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// Name returns the name of the environment variable that the value of the argument is read from.
+// If there is no prefix, then only the arguments in Keys are read, and an empty string is returned for every other argument.
+func (e *EnvReader) Name(arg Argument) string {
+	if name, ok := e.Keys[arg.Key]; ok {
+		return name
+	}
+
+	if e.Prefix == "" {
+		return ""
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, arg.Key)
+
+	return e.Prefix + name
+}
+
+func (e *EnvReader) get(arg Argument) (string, error) {
+	name := e.Name(arg)
+	if name == "" {
+		return "", ErrorNotFound
+	}
+
+	if v, ok := e.lookup(name); ok {
+		return v, nil
+	}
+
+	return "", fmt.Errorf("%w: environment variable '%s' is not set", ErrorNotFound, name)
+}
+
+func (e *EnvReader) GetString(arg Argument) (string, error) {
+	return e.get(arg)
+}
+
+func (e *EnvReader) GetInt64(arg Argument) (int64, error) {
+	val, err := e.get(arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(val, 10, 64)
+}
+
+func (e *EnvReader) GetFloat64(arg Argument) (float64, error) {
+	val, err := e.get(arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(val, 64)
+}
+
+func (e *EnvReader) GetBool(arg Argument) (bool, error) {
+	val, err := e.get(arg)
+	if err != nil {
+		return false, err
+	}
+
+	return strconv.ParseBool(val)
+}
+
+func (e *EnvReader) GetFile(arg Argument) (*os.File, error) {
+	val, err := e.get(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(val)
+}
+
+func (e *EnvReader) GetDirectory(arg Argument) (fs.FS, error) {
+	val, err := e.get(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.DirFS(val), nil
+}
+
+func (e *EnvReader) GetDirectoryString(arg Argument) (string, error) {
+	return e.get(arg)
+}
+
+func (e *EnvReader) Exists(arg Argument) (bool, error) {
+	name := e.Name(arg)
+	if name == "" {
+		return false, nil
+	}
+
+	_, ok := e.lookup(name)
+	return ok, nil
+}
@@ -0,0 +1,107 @@
+package state_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/scribe/state"
+)
+
+func TestEnvReader(t *testing.T) {
+	t.Run("Arguments should be read from prefixed environment variables", func(t *testing.T) {
+		t.Setenv("SCRIBE_TEST_GIT_BRANCH", "main")
+		t.Setenv("SCRIBE_TEST_COUNT", "12")
+		t.Setenv("DRONE_COMMIT_SHA", "abcd")
+
+		r := state.NewEnvReader("SCRIBE_TEST_", map[string]string{
+			"git-commit-sha": "DRONE_COMMIT_SHA",
+		})
+
+		if v, err := r.GetString(state.NewStringArgument("git-branch")); err != nil || v != "main" {
+			t.Fatalf("Expected 'main' but got '%s', '%v'", v, err)
+		}
+		if v, err := r.GetInt64(state.NewInt64Argument("count")); err != nil || v != 12 {
+			t.Fatalf("Expected '12' but got '%d', '%v'", v, err)
+		}
+		if v, err := r.GetString(state.NewStringArgument("git-commit-sha")); err != nil || v != "abcd" {
+			t.Fatalf("Expected 'abcd' but got '%s', '%v'", v, err)
+		}
+
+		arg := state.NewStringArgument("missing")
+		if exists, err := r.Exists(arg); err != nil || exists {
+			t.Fatalf("Expected argument to not exist, but got '%t', '%v'", exists, err)
+		}
+		if _, err := r.GetString(arg); !errors.Is(err, state.ErrorNotFound) {
+			t.Fatalf("Expected error '%v' but got '%v'", state.ErrorNotFound, err)
+		}
+	})
+
+	t.Run("Without a prefix, only the mapped arguments should be read", func(t *testing.T) {
+		t.Setenv("GIT_BRANCH", "main")
+		r := state.NewEnvReader("", nil)
+
+		if exists, err := r.Exists(state.NewStringArgument("git-branch")); err != nil || exists {
+			t.Fatalf("Expected argument to not exist, but got '%t', '%v'", exists, err)
+		}
+	})
+
+	t.Run("Arguments should be read from a .env file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".env")
+		contents := "# comment\n\nSCRIBE_ARG_GIT_BRANCH=main\nexport SCRIBE_ARG_MESSAGE=\"hello\\nworld\"\nSCRIBE_ARG_QUOTED='a=b'\n"
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := state.NewEnvFileReader(path, "SCRIBE_ARG_", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[string]string{
+			"git-branch": "main",
+			"message":    "hello\nworld",
+			"quoted":     "a=b",
+		}
+
+		for k, v := range expected {
+			if value, err := r.GetString(state.NewStringArgument(k)); err != nil || value != v {
+				t.Fatalf("Expected '%s' but got '%s', '%v'", v, value, err)
+			}
+		}
+	})
+
+	t.Run("Invalid lines in a .env file should return an error", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".env")
+		if err := os.WriteFile(path, []byte("SCRIBE_ARG_GIT_BRANCH\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := state.NewEnvFileReader(path, "SCRIBE_ARG_", nil); err == nil {
+			t.Fatal("Expected an error but got nil")
+		}
+	})
+}
+
+func TestArgMapFileReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "args.yaml")
+	if err := os.WriteFile(path, []byte("git-branch: main\ncount: 12\nenabled: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := state.NewArgMapFileReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := r.GetString(state.NewStringArgument("git-branch")); err != nil || v != "main" {
+		t.Fatalf("Expected 'main' but got '%s', '%v'", v, err)
+	}
+	if v, err := r.GetInt64(state.NewInt64Argument("count")); err != nil || v != 12 {
+		t.Fatalf("Expected '12' but got '%d', '%v'", v, err)
+	}
+	if v, err := r.GetBool(state.NewBoolArgument("enabled")); err != nil || !v {
+		t.Fatalf("Expected 'true' but got '%t', '%v'", v, err)
+	}
+}
@@ -22,6 +22,7 @@ type stateValue struct {
 }
 
 // FilesystemState stores state in a JSON file on the filesystem.
+// The file can be shared by multiple processes, like steps that run in separate containers with the same volume mounted. Writes are atomic and are serialized using a lock file next to the state file.
 type FilesystemState struct {
 	path string
 	mtx  *sync.Mutex
@@ -45,29 +46,88 @@ func (f *FilesystemState) fsStatePath() (string, error) {
 	return path, nil
 }
 
-func (f *FilesystemState) openr() (*os.File, error) {
-	return os.Open(f.path)
+// values reads every value in the state file. If the file does not exist or is empty, then the state is empty.
+func (f *FilesystemState) values() (map[string]stateValue, error) {
+	state := map[string]stateValue{}
+
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return state, nil
+		}
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return state, nil
+	}
+
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("%w: '%s': %s", ErrorCorruptState, f.path, err)
+	}
+
+	return state, nil
 }
 
-func (f *FilesystemState) openw() (*os.File, error) {
-	return os.Create(f.path)
+// lock acquires a lock on the '.lock' file next to the state file, which prevents other processes that use the same state file from writing to it at the same time.
+// The returned function releases the lock.
+func (f *FilesystemState) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(f.path), os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(f.path+".lock", os.O_CREATE|os.O_RDWR, os.FileMode(0644))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error locking state file '%s': %w", f.path, err)
+	}
+
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// write atomically replaces the state file by writing the state to a temporary file in the same folder and renaming it.
+// Processes that read the state while it is being written will read either the old or the new state, but never a partially written one.
+func (f *FilesystemState) write(state map[string]stateValue) error {
+	w, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+"-")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(w.Name())
+
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		w.Close()
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(w.Name(), f.path)
 }
 
 func (f *FilesystemState) setValue(arg Argument, value any) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
-	r, err := f.openr()
+
+	unlock, err := f.lock()
 	if err != nil {
-		if !errors.Is(err, fs.ErrNotExist) {
-			return err
-		}
+		return err
 	}
+	defer unlock()
 
-	state := map[string]stateValue{}
-
-	// Error ignored intentionally. If there's an error, it's likely that the file is empty. We'll overwrite it.
-	json.NewDecoder(r).Decode(&state)
-	r.Close()
+	state, err := f.values()
+	if err != nil {
+		return err
+	}
 
 	// TODO: Do we really want to not allow overriding?
 	// hmm
@@ -75,44 +135,29 @@ func (f *FilesystemState) setValue(arg Argument, value any) error {
 	// 	return ErrorKeyExists
 	// }
 
-	w, err := f.openw()
-	if err != nil {
-		return err
-	}
-
-	defer w.Close()
-
 	state[arg.Key] = stateValue{
 		Argument: arg,
 		Value:    value,
 	}
 
-	return json.NewEncoder(w).Encode(state)
+	return f.write(state)
 }
 
 func (f *FilesystemState) getValue(arg Argument) (any, error) {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
-	file, err := f.openr()
+
+	state, err := f.values()
 	if err != nil {
 		return "", err
 	}
 
-	defer file.Close()
-
-	state := map[string]stateValue{}
-
-	if err := json.NewDecoder(file).Decode(&state); err != nil {
-		return "", ErrorEmptyState
-	}
-
 	v, ok := state[arg.Key]
 	if !ok {
 		return "", ErrorNotFound
 	}
 
 	return v.Value, nil
-
 }
 
 func (f *FilesystemState) GetString(arg Argument) (string, error) {
@@ -0,0 +1,100 @@
+package state_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/grafana/scribe/state"
+)
+
+func TestFilesystemState(t *testing.T) {
+	t.Run("A state file that does not exist should be empty", func(t *testing.T) {
+		s, err := state.NewFilesystemState(filepath.Join(t.TempDir(), "build", "state.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		arg := state.NewStringArgument("string")
+		if exists, err := s.Exists(arg); err != nil || exists {
+			t.Fatalf("Expected argument to not exist, but got '%t', '%v'", exists, err)
+		}
+
+		if err := s.SetString(arg, "value"); err != nil {
+			t.Fatal(err)
+		}
+
+		if v, err := s.GetString(arg); err != nil || v != "value" {
+			t.Fatalf("Expected 'value' but got '%s', '%v'", v, err)
+		}
+	})
+
+	t.Run("A corrupt state file should return an error instead of being overwritten", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		if err := os.WriteFile(path, []byte(`{"string": {"value": "val`), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := state.NewFilesystemState(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		arg := state.NewStringArgument("string")
+		if _, err := s.GetString(arg); !errors.Is(err, state.ErrorCorruptState) {
+			t.Fatalf("Expected error '%v' but got '%v'", state.ErrorCorruptState, err)
+		}
+		if err := s.SetString(arg, "value"); !errors.Is(err, state.ErrorCorruptState) {
+			t.Fatalf("Expected error '%v' but got '%v'", state.ErrorCorruptState, err)
+		}
+	})
+
+	t.Run("Concurrent writes should not overwrite each other", func(t *testing.T) {
+		var (
+			path = filepath.Join(t.TempDir(), "state.json")
+			wg   = &sync.WaitGroup{}
+			n    = 20
+		)
+
+		errs := make(chan error, n)
+		for i := 0; i < n; i++ {
+			// Every write uses its own state to simulate steps running in different processes.
+			s, err := state.NewFilesystemState(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wg.Add(1)
+			go func(s *state.FilesystemState, i int) {
+				defer wg.Done()
+				errs <- s.SetInt64(state.NewInt64Argument(fmt.Sprintf("arg-%d", i)), int64(i))
+			}(s, i)
+		}
+
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		s, err := state.NewFilesystemState(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < n; i++ {
+			v, err := s.GetInt64(state.NewInt64Argument(fmt.Sprintf("arg-%d", i)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != int64(i) {
+				t.Fatalf("Expected '%d' but got '%d'", i, v)
+			}
+		}
+	})
+}
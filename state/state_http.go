@@ -0,0 +1,314 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/grafana/scribe/stringutil"
+	"github.com/grafana/scribe/swhttp"
+	"github.com/grafana/scribe/tarfs"
+)
+
+// HTTPState stores state in a state Server, like the one started with 'scribe state serve'.
+// Files and directories are streamed to and from the server, and are downloaded into a temporary folder when they are read.
+type HTTPState struct {
+	URL    string
+	Client *http.Client
+
+	tmp string
+	mtx *sync.Mutex
+}
+
+func NewHTTPState(u string) *HTTPState {
+	return &HTTPState{
+		URL:    strings.TrimSuffix(u, "/"),
+		Client: &swhttp.DefaultClient,
+		mtx:    &sync.Mutex{},
+	}
+}
+
+// tmpDir returns the local folder where files and directories from the server are downloaded.
+func (h *HTTPState) tmpDir() (string, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.tmp != "" {
+		return h.tmp, nil
+	}
+
+	dir, err := os.MkdirTemp("", "scribe-state-")
+	if err != nil {
+		return "", err
+	}
+
+	h.tmp = dir
+	return dir, nil
+}
+
+func (h *HTTPState) url(kind string, arg Argument, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("type", arg.Type.String())
+
+	return fmt.Sprintf("%s/%s/%s?%s", h.URL, kind, url.PathEscape(arg.Key), query.Encode())
+}
+
+// do sends the request and returns the response if it was successful. A '404 Not Found' response returns ErrorNotFound.
+func (h *HTTPState) do(method, u string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrorNotFound
+	}
+
+	if err := swhttp.HandleResponse(res, nil); err != nil {
+		res.Body.Close()
+		return nil, fmt.Errorf("error sending '%s' request to state server: %w", method, err)
+	}
+
+	return res, nil
+}
+
+func (h *HTTPState) setValue(arg Argument, value any) error {
+	body, err := json.Marshal(httpValue{
+		Value: value,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := h.do(http.MethodPut, h.url("values", arg, nil), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
+
+func (h *HTTPState) getValue(arg Argument) (any, error) {
+	res, err := h.do(http.MethodGet, h.url("values", arg, nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	v := httpValue{}
+	if err := json.NewDecoder(res.Body).Decode(&v); err != nil {
+		return nil, fmt.Errorf("error decoding value from state server: %w", err)
+	}
+
+	return v.Value, nil
+}
+
+// download writes the response body to a new file in the temporary directory.
+func (h *HTTPState) download(arg Argument, kind string) (*os.File, error) {
+	res, err := h.do(http.MethodGet, h.url(kind, arg, nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	dir, err := h.tmpDir()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp(dir, stringutil.Slugify(arg.Key)+"-")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(file, res.Body); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (h *HTTPState) GetString(arg Argument) (string, error) {
+	v, err := h.getValue(arg)
+	if err != nil {
+		return "", err
+	}
+
+	return v.(string), nil
+}
+
+func (h *HTTPState) SetString(arg Argument, value string) error {
+	return h.setValue(arg, value)
+}
+
+func (h *HTTPState) GetInt64(arg Argument) (int64, error) {
+	v, err := h.getValue(arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(v.(float64)), nil
+}
+
+func (h *HTTPState) SetInt64(arg Argument, value int64) error {
+	return h.setValue(arg, value)
+}
+
+func (h *HTTPState) GetFloat64(arg Argument) (float64, error) {
+	v, err := h.getValue(arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return v.(float64), nil
+}
+
+func (h *HTTPState) SetFloat64(arg Argument, value float64) error {
+	return h.setValue(arg, value)
+}
+
+func (h *HTTPState) GetBool(arg Argument) (bool, error) {
+	v, err := h.getValue(arg)
+	if err != nil {
+		return false, err
+	}
+
+	return v.(bool), nil
+}
+
+func (h *HTTPState) SetBool(arg Argument, value bool) error {
+	return h.setValue(arg, value)
+}
+
+// GetFile downloads the file from the server.
+func (h *HTTPState) GetFile(arg Argument) (*os.File, error) {
+	return h.download(arg, "files")
+}
+
+func (h *HTTPState) SetFile(arg Argument, value string) error {
+	file, err := os.Open(value)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return h.SetFileReader(arg, file)
+}
+
+func (h *HTTPState) SetFileReader(arg Argument, value io.Reader) error {
+	res, err := h.do(http.MethodPut, h.url("files", arg, nil), value)
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
+
+// GetDirectory downloads and extracts the directory from the server.
+// Unpackaged directories are not stored on the server, so only their path is retrieved.
+func (h *HTTPState) GetDirectory(arg Argument) (fs.FS, error) {
+	if arg.Type == ArgumentTypeUnpackagedFS {
+		path, err := h.GetDirectoryString(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		return os.DirFS(path), nil
+	}
+
+	file, err := h.download(arg, "directories")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	dir, err := h.tmpDir()
+	if err != nil {
+		return nil, err
+	}
+
+	destination := filepath.Join(dir, stringutil.Slugify(arg.Key), stringutil.Random(8))
+	if err := os.MkdirAll(destination, os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+
+	if err := tarfs.Untar(destination, file); err != nil {
+		return nil, err
+	}
+
+	return os.DirFS(destination), nil
+}
+
+// GetDirectoryString retrieves the original directory path.
+func (h *HTTPState) GetDirectoryString(arg Argument) (string, error) {
+	return h.GetString(arg)
+}
+
+// setDirectory streams the directory to the server as a '.tar.gz' while it is being created.
+func (h *HTTPState) setDirectory(arg Argument, value string) error {
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(tarfs.Write(w, os.DirFS(value)))
+	}()
+	defer r.Close()
+
+	res, err := h.do(http.MethodPut, h.url("directories", arg, url.Values{"path": []string{value}}), r)
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
+
+func (h *HTTPState) SetDirectory(arg Argument, value string) error {
+	info, err := os.Stat(value)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("directory '%s' does not exist", value)
+	}
+
+	if arg.Type == ArgumentTypeFS {
+		return h.setDirectory(arg, value)
+	}
+
+	return h.setValue(arg, value)
+}
+
+func (h *HTTPState) Exists(arg Argument) (bool, error) {
+	res, err := h.do(http.MethodHead, h.url("values", arg, nil), nil)
+	if err == nil {
+		return true, res.Body.Close()
+	}
+
+	if errors.Is(err, ErrorNotFound) {
+		return false, nil
+	}
+
+	return false, err
+}
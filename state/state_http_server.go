@@ -0,0 +1,245 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/scribe/stringutil"
+	"github.com/grafana/scribe/tarfs"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrorInvalidValue is returned by the Server when a request contains a value that can not be used for the argument.
+var ErrorInvalidValue = errors.New("invalid value for argument")
+
+// httpValue is the body of requests and responses for values in the HTTP state.
+type httpValue struct {
+	Value any `json:"value"`
+}
+
+// Server exposes a StateHandler over HTTP so that pipelines which run steps on different machines or containers can share the same state. Use the HTTPState to connect to it.
+// Every argument has its own path, and the type of the argument is provided in the 'type' query parameter:
+//
+//   - '/values/{key}' gets (GET), sets (PUT), or checks if a value exists (HEAD).
+//   - '/files/{key}' downloads (GET) or uploads (PUT) the contents of a file.
+//   - '/directories/{key}' downloads (GET) or uploads (PUT) a directory as a '.tar.gz' stream.
+type Server struct {
+	Handler StateHandler
+	// Dir is the folder where uploaded directories are extracted before they are added to the Handler.
+	Dir string
+	Log logrus.FieldLogger
+}
+
+// NewServer creates a new Server. If 'dir' is empty, then uploaded directories are extracted to a new temporary folder.
+func NewServer(handler StateHandler, dir string, log logrus.FieldLogger) (*Server, error) {
+	if dir == "" {
+		d, err := os.MkdirTemp("", "scribe-state-server-")
+		if err != nil {
+			return nil, err
+		}
+		dir = d
+	}
+
+	return &Server{
+		Handler: handler,
+		Dir:     dir,
+		Log:     log,
+	}, nil
+}
+
+// directoryPathArgument is the argument that stores the original path of an uploaded directory, as the directory in the Handler is the folder it was extracted to.
+func directoryPathArgument(arg Argument) Argument {
+	return NewStringArgument(fmt.Sprintf("scribe-state-server/%s/path", arg.Key))
+}
+
+func (s *Server) error(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrorNotFound), errors.Is(err, ErrorEmptyState), errors.Is(err, fs.ErrNotExist):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrorInvalidValue):
+		code = http.StatusBadRequest
+	}
+
+	if code == http.StatusInternalServerError {
+		s.Log.WithError(err).Errorln("error handling state request")
+	}
+
+	http.Error(w, err.Error(), code)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if len(p) != 2 || p[1] == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	t, err := ParseArgumentType(r.URL.Query().Get("type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	arg := Argument{
+		Type: t,
+		Key:  p[1],
+	}
+
+	s.Log.Debugf("%s %s (%s)", r.Method, p[0], arg.Key)
+
+	switch {
+	case p[0] == "values" && r.Method == http.MethodHead:
+		err = s.exists(w, arg)
+	case p[0] == "values" && r.Method == http.MethodGet:
+		err = s.getValue(w, arg)
+	case p[0] == "values" && r.Method == http.MethodPut:
+		err = s.setValue(r.Body, arg)
+	case p[0] == "files" && r.Method == http.MethodGet:
+		err = s.getFile(w, arg)
+	case p[0] == "files" && r.Method == http.MethodPut:
+		err = s.Handler.SetFileReader(arg, r.Body)
+	case p[0] == "directories" && r.Method == http.MethodGet:
+		err = s.getDirectory(w, arg)
+	case p[0] == "directories" && r.Method == http.MethodPut:
+		err = s.setDirectory(r.Body, arg, r.URL.Query().Get("path"))
+	default:
+		http.Error(w, fmt.Sprintf("%s '%s' is not supported", r.Method, r.URL.Path), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err != nil {
+		s.error(w, err)
+	}
+}
+
+func (s *Server) exists(w http.ResponseWriter, arg Argument) error {
+	exists, err := s.Handler.Exists(arg)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrorNotFound
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) getValue(w http.ResponseWriter, arg Argument) error {
+	var (
+		value any
+		err   error
+	)
+
+	switch arg.Type {
+	case ArgumentTypeString, ArgumentTypeSecret, ArgumentTypeStringSlice, ArgumentTypeStringMap, ArgumentTypeJSON:
+		value, err = s.Handler.GetString(arg)
+	case ArgumentTypeInt64:
+		value, err = s.Handler.GetInt64(arg)
+	case ArgumentTypeFloat64:
+		value, err = s.Handler.GetFloat64(arg)
+	case ArgumentTypeBool:
+		value, err = s.Handler.GetBool(arg)
+	case ArgumentTypeFS:
+		value, err = s.Handler.GetString(directoryPathArgument(arg))
+	case ArgumentTypeUnpackagedFS:
+		value, err = s.Handler.GetDirectoryString(arg)
+	default:
+		return fmt.Errorf("arguments of type '%s' do not have a value", arg.Type)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(httpValue{
+		Value: value,
+	})
+}
+
+func (s *Server) setValue(r io.Reader, arg Argument) error {
+	v := httpValue{}
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return fmt.Errorf("%w: error decoding value: %s", ErrorInvalidValue, err)
+	}
+
+	switch value := v.Value.(type) {
+	case string:
+		// Structured values are encoded as JSON strings by the State.
+		if ArgumentTypesEqual(arg, ArgumentTypeString, ArgumentTypeSecret, ArgumentTypeStringSlice, ArgumentTypeStringMap, ArgumentTypeJSON) {
+			return s.Handler.SetString(arg, value)
+		}
+		if arg.Type == ArgumentTypeUnpackagedFS {
+			// Unpackaged directories should exist on every machine, but not necessarily on the server, so only the path is stored.
+			return s.Handler.SetString(arg, value)
+		}
+	case float64:
+		if arg.Type == ArgumentTypeInt64 {
+			return s.Handler.SetInt64(arg, int64(value))
+		}
+		if arg.Type == ArgumentTypeFloat64 {
+			return s.Handler.SetFloat64(arg, value)
+		}
+	case bool:
+		if arg.Type == ArgumentTypeBool {
+			return s.Handler.SetBool(arg, value)
+		}
+	}
+
+	return fmt.Errorf("%w: '%v' is not valid for arguments of type '%s'", ErrorInvalidValue, v.Value, arg.Type)
+}
+
+func (s *Server) getFile(w http.ResponseWriter, arg Argument) error {
+	file, err := s.Handler.GetFile(arg)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func (s *Server) getDirectory(w http.ResponseWriter, arg Argument) error {
+	dir, err := s.Handler.GetDirectory(arg)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	return tarfs.Write(w, dir)
+}
+
+// setDirectory extracts the '.tar.gz' stream into a new folder and adds it to the Handler.
+// The original path of the directory is stored separately so that GetDirectoryString returns the path on the machine that uploaded it.
+func (s *Server) setDirectory(r io.Reader, arg Argument, path string) error {
+	if path == "" {
+		return fmt.Errorf("%w: directory upload for '%s' does not contain a path", ErrorInvalidValue, arg.Key)
+	}
+
+	dir := filepath.Join(s.Dir, stringutil.Slugify(arg.Key), stringutil.Random(8))
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	if err := tarfs.Untar(dir, r); err != nil {
+		return fmt.Errorf("error extracting directory: %w", err)
+	}
+
+	if err := s.Handler.SetDirectory(arg, dir); err != nil {
+		return err
+	}
+
+	return s.Handler.SetString(directoryPathArgument(arg), path)
+}
@@ -0,0 +1,159 @@
+package state_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/swfs"
+	"github.com/sirupsen/logrus"
+)
+
+func newHTTPState(t *testing.T) *state.HTTPState {
+	t.Helper()
+	dir := t.TempDir()
+
+	handler, err := state.NewFilesystemState(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := state.NewServer(handler, filepath.Join(dir, "uploads"), logrus.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	s := state.NewHTTPState(ts.URL)
+	s.Client = ts.Client()
+	return s
+}
+
+func TestHTTPState(t *testing.T) {
+	t.Run("Values should be stored in the server's state", func(t *testing.T) {
+		var (
+			s = newHTTPState(t)
+
+			str = state.NewStringArgument("string")
+			i   = state.NewInt64Argument("int")
+			f   = state.NewFloat64Argument("float")
+			b   = state.NewBoolArgument("bool")
+			dir = state.NewUnpackagedDirectoryArgument("source")
+		)
+
+		if exists, err := s.Exists(str); err != nil || exists {
+			t.Fatalf("Expected argument to not exist, but got '%t', '%v'", exists, err)
+		}
+		if _, err := s.GetString(str); !errors.Is(err, state.ErrorNotFound) {
+			t.Fatalf("Expected error '%v' but got '%v'", state.ErrorNotFound, err)
+		}
+
+		if err := s.SetString(str, "value"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetInt64(i, 12); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetFloat64(f, 1.5); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetBool(b, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetDirectory(dir, "."); err != nil {
+			t.Fatal(err)
+		}
+
+		if exists, err := s.Exists(str); err != nil || !exists {
+			t.Fatalf("Expected argument to exist, but got '%t', '%v'", exists, err)
+		}
+		if v, err := s.GetString(str); err != nil || v != "value" {
+			t.Fatalf("Expected 'value' but got '%s', '%v'", v, err)
+		}
+		if v, err := s.GetInt64(i); err != nil || v != 12 {
+			t.Fatalf("Expected '12' but got '%d', '%v'", v, err)
+		}
+		if v, err := s.GetFloat64(f); err != nil || v != 1.5 {
+			t.Fatalf("Expected '1.5' but got '%f', '%v'", v, err)
+		}
+		if v, err := s.GetBool(b); err != nil || !v {
+			t.Fatalf("Expected 'true' but got '%t', '%v'", v, err)
+		}
+		if v, err := s.GetDirectoryString(dir); err != nil || v != "." {
+			t.Fatalf("Expected '.' but got '%s', '%v'", v, err)
+		}
+	})
+
+	t.Run("Files and directories should be streamed to and from the server", func(t *testing.T) {
+		var (
+			s    = newHTTPState(t)
+			file = state.NewFileArgument("file")
+			dir  = state.NewDirectoryArgument("dir")
+		)
+
+		if err := s.SetFileReader(file, strings.NewReader("file contents")); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := s.GetFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "file contents" {
+			t.Fatalf("Expected 'file contents' but got '%s'", string(b))
+		}
+
+		src := filepath.Join("..", "tarfs", "testdir")
+		if err := s.SetDirectory(dir, src); err != nil {
+			t.Fatal(err)
+		}
+
+		d, err := s.GetDirectory(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		equal, err := swfs.Equal(os.DirFS(src), d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equal {
+			t.Fatal("Expected the directory from the state to equal the original directory")
+		}
+
+		if v, err := s.GetDirectoryString(dir); err != nil || v != src {
+			t.Fatalf("Expected '%s' but got '%s', '%v'", src, v, err)
+		}
+	})
+
+	t.Run("Invalid requests should return an error", func(t *testing.T) {
+		s := newHTTPState(t)
+
+		res, err := s.Client.Get(s.URL + "/values/string?type=unknown")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected status '%d' but got '%d'", http.StatusBadRequest, res.StatusCode)
+		}
+
+		if err := s.SetString(state.NewInt64Argument("int"), "not a number"); err == nil {
+			t.Fatal("Expected an error when setting a string value for an int argument")
+		}
+	})
+}
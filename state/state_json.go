@@ -0,0 +1,221 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Structured values, like string slices, string maps, and JSON values, are encoded as JSON and stored as strings in the StateHandler, so every StateHandler supports them.
+// Values that are provided by the user, like with the '-arg' flag or stdin, can also use a simpler format:
+//   - String slices: 'a,b,c'
+//   - String maps: 'key=value,key2=value2'
+
+// getEncoded reads the encoded value of a structured argument and decodes it using the decode function.
+// Like the other getters, if the value is not in the state, then it is read from the Fallback readers. The returned bool is true if it was.
+func (s *State) getEncoded(arg Argument, decode func(string) error) (bool, error) {
+	value, err := s.Handler.GetString(arg)
+	if err == nil {
+		return false, decode(value)
+	}
+
+	for _, v := range s.Fallback {
+		s.Log.WithError(err).Debugln("state returned an error; attempting fallback state")
+		val, err := v.GetString(arg)
+		if err == nil {
+			return true, decode(val)
+		}
+
+		s.Log.WithError(err).Debugln("fallback state reader returned an error")
+	}
+
+	return false, err
+}
+
+// DecodeStringSlice decodes a string slice from a JSON array or a comma-separated list.
+func DecodeStringSlice(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") {
+		v := []string{}
+		if err := json.Unmarshal([]byte(value), &v); err == nil {
+			return v, nil
+		}
+	}
+
+	if value == "" {
+		return []string{}, nil
+	}
+
+	v := strings.Split(value, ",")
+	for i := range v {
+		v[i] = strings.TrimSpace(v[i])
+	}
+
+	return v, nil
+}
+
+// DecodeStringMap decodes a string map from a JSON object or a comma-separated list of 'key=value' pairs.
+func DecodeStringMap(value string) (map[string]string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "{") {
+		v := map[string]string{}
+		if err := json.Unmarshal([]byte(value), &v); err == nil {
+			return v, nil
+		}
+	}
+
+	v := map[string]string{}
+	if value == "" {
+		return v, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		p := strings.SplitN(pair, "=", 2)
+		if len(p) != 2 {
+			return nil, fmt.Errorf("'%s' is not a 'key=value' pair", pair)
+		}
+
+		v[strings.TrimSpace(p[0])] = strings.TrimSpace(p[1])
+	}
+
+	return v, nil
+}
+
+// GetStringSlice attempts to get the string slice from the state.
+func (s *State) GetStringSlice(arg Argument) ([]string, error) {
+	if !ArgumentTypesEqual(arg, ArgumentTypeStringSlice) {
+		return nil, fmt.Errorf("attempted to get string slice from state for wrong argument type '%s'", arg.Type)
+	}
+
+	var value []string
+	fallback, err := s.getEncoded(arg, func(v string) error {
+		val, err := DecodeStringSlice(v)
+		value = val
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if fallback {
+		if err := s.SetStringSlice(arg, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+func (s *State) MustGetStringSlice(arg Argument) []string {
+	val, err := s.GetStringSlice(arg)
+	if err != nil {
+		panic(err)
+	}
+
+	return val
+}
+
+// SetStringSlice attempts to set the string slice into the state.
+func (s *State) SetStringSlice(arg Argument, value []string) error {
+	if !ArgumentTypesEqual(arg, ArgumentTypeStringSlice) {
+		return fmt.Errorf("attempted to set string slice in state for wrong argument type '%s'", arg.Type)
+	}
+
+	return s.setEncoded(arg, value)
+}
+
+// GetStringMap attempts to get the string map from the state.
+func (s *State) GetStringMap(arg Argument) (map[string]string, error) {
+	if !ArgumentTypesEqual(arg, ArgumentTypeStringMap) {
+		return nil, fmt.Errorf("attempted to get string map from state for wrong argument type '%s'", arg.Type)
+	}
+
+	var value map[string]string
+	fallback, err := s.getEncoded(arg, func(v string) error {
+		val, err := DecodeStringMap(v)
+		value = val
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if fallback {
+		if err := s.SetStringMap(arg, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+func (s *State) MustGetStringMap(arg Argument) map[string]string {
+	val, err := s.GetStringMap(arg)
+	if err != nil {
+		panic(err)
+	}
+
+	return val
+}
+
+// SetStringMap attempts to set the string map into the state.
+func (s *State) SetStringMap(arg Argument, value map[string]string) error {
+	if !ArgumentTypesEqual(arg, ArgumentTypeStringMap) {
+		return fmt.Errorf("attempted to set string map in state for wrong argument type '%s'", arg.Type)
+	}
+
+	return s.setEncoded(arg, value)
+}
+
+func (s *State) setEncoded(arg Argument, value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error encoding value for argument '%s': %w", arg.Key, err)
+	}
+
+	return s.Handler.SetString(arg, string(b))
+}
+
+// GetJSON attempts to get the JSON value from the state and decodes it into a value of type T.
+func GetJSON[T any](s *State, arg Argument) (T, error) {
+	var value T
+	if !ArgumentTypesEqual(arg, ArgumentTypeJSON) {
+		return value, fmt.Errorf("attempted to get JSON from state for wrong argument type '%s'", arg.Type)
+	}
+
+	fallback, err := s.getEncoded(arg, func(v string) error {
+		if err := json.Unmarshal([]byte(v), &value); err != nil {
+			return fmt.Errorf("error decoding JSON for argument '%s': %w", arg.Key, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return value, err
+	}
+
+	if fallback {
+		if err := SetJSON(s, arg, value); err != nil {
+			return value, err
+		}
+	}
+
+	return value, nil
+}
+
+func MustGetJSON[T any](s *State, arg Argument) T {
+	val, err := GetJSON[T](s, arg)
+	if err != nil {
+		panic(err)
+	}
+
+	return val
+}
+
+// SetJSON attempts to encode the value as JSON and set it into the state.
+func SetJSON(s *State, arg Argument, value any) error {
+	if !ArgumentTypesEqual(arg, ArgumentTypeJSON) {
+		return fmt.Errorf("attempted to set JSON in state for wrong argument type '%s'", arg.Type)
+	}
+
+	return s.setEncoded(arg, value)
+}
@@ -0,0 +1,186 @@
+package state_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/state"
+	"github.com/sirupsen/logrus"
+)
+
+type versionMatrix struct {
+	Go       []string `json:"go"`
+	Platform string   `json:"platform"`
+}
+
+func newJSONState(t *testing.T, handler state.StateHandler, fallback ...state.StateReader) *state.State {
+	t.Helper()
+	if handler == nil {
+		h, err := state.NewFilesystemState(filepath.Join(t.TempDir(), "state.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		handler = h
+	}
+
+	return &state.State{
+		Handler:  handler,
+		Fallback: fallback,
+		Log:      logrus.New(),
+	}
+}
+
+func testStructuredValues(t *testing.T, s *state.State) {
+	t.Helper()
+	var (
+		list   = state.NewStringSliceArgument("artifacts")
+		m      = state.NewStringMapArgument("versions")
+		matrix = state.NewJSONArgument("matrix")
+	)
+
+	if err := s.SetStringSlice(list, []string{"a.tar.gz", "b.tar.gz"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetStringMap(m, map[string]string{"go": "1.19"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.SetJSON(s, matrix, versionMatrix{Go: []string{"1.18", "1.19"}, Platform: "linux"}); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := s.GetStringSlice(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a.tar.gz", "b.tar.gz"}, l); diff != "" {
+		t.Fatal(diff)
+	}
+
+	v, err := s.GetStringMap(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"go": "1.19"}, v); diff != "" {
+		t.Fatal(diff)
+	}
+
+	mx, err := state.GetJSON[versionMatrix](s, matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(versionMatrix{Go: []string{"1.18", "1.19"}, Platform: "linux"}, mx); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestStructuredValues(t *testing.T) {
+	t.Run("Structured values should be stored in a FilesystemState", func(t *testing.T) {
+		testStructuredValues(t, newJSONState(t, nil))
+	})
+
+	t.Run("Structured values should be stored in an HTTPState", func(t *testing.T) {
+		testStructuredValues(t, newJSONState(t, newHTTPState(t)))
+	})
+
+	t.Run("Structured values should be parsed from the ArgMap", func(t *testing.T) {
+		s := newJSONState(t, nil, state.NewArgMapReader(args.ArgMap{
+			"list":   "a, b,c",
+			"map":    "a=1,b=2",
+			"json":   `{"go": ["1.19"], "platform": "linux"}`,
+			"jsonl":  `["a", "b"]`,
+			"jsonm":  `{"a": "1"}`,
+			"broken": "a",
+		}))
+
+		l, err := s.GetStringSlice(state.NewStringSliceArgument("list"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"a", "b", "c"}, l); diff != "" {
+			t.Fatal(diff)
+		}
+
+		l, err = s.GetStringSlice(state.NewStringSliceArgument("jsonl"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"a", "b"}, l); diff != "" {
+			t.Fatal(diff)
+		}
+
+		m, err := s.GetStringMap(state.NewStringMapArgument("map"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(map[string]string{"a": "1", "b": "2"}, m); diff != "" {
+			t.Fatal(diff)
+		}
+
+		m, err = s.GetStringMap(state.NewStringMapArgument("jsonm"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(map[string]string{"a": "1"}, m); diff != "" {
+			t.Fatal(diff)
+		}
+
+		mx, err := state.GetJSON[versionMatrix](s, state.NewJSONArgument("json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(versionMatrix{Go: []string{"1.19"}, Platform: "linux"}, mx); diff != "" {
+			t.Fatal(diff)
+		}
+
+		// Values read from fallback readers should be stored in the state as JSON.
+		if v, err := s.Handler.GetString(state.NewStringSliceArgument("list")); err != nil || v != `["a","b","c"]` {
+			t.Fatalf("Expected the list to be stored as JSON but got '%s', '%v'", v, err)
+		}
+
+		if _, err := s.GetStringMap(state.NewStringMapArgument("broken")); err == nil {
+			t.Fatal("Expected an error for a map value that is not a list of 'key=value' pairs")
+		}
+	})
+
+	t.Run("Lists and maps in a YAML argument file should be read as structured values", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "args.yaml")
+		if err := os.WriteFile(path, []byte("artifacts: [a, b]\nversions:\n  go: 1.19\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := state.NewArgMapFileReader(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s := newJSONState(t, nil, r)
+		l, err := s.GetStringSlice(state.NewStringSliceArgument("artifacts"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"a", "b"}, l); diff != "" {
+			t.Fatal(diff)
+		}
+
+		m, err := s.GetStringMap(state.NewStringMapArgument("versions"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(map[string]string{"go": "1.19"}, m); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Structured values should not be used with the wrong argument type", func(t *testing.T) {
+		s := newJSONState(t, nil)
+		if err := s.SetStringSlice(state.NewStringArgument("string"), []string{"a"}); err == nil {
+			t.Fatal("Expected an error but got nil")
+		}
+		if err := state.SetJSON(s, state.NewStringMapArgument("map"), map[string]string{}); err == nil {
+			t.Fatal("Expected an error but got nil")
+		}
+	})
+}
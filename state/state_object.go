@@ -0,0 +1,396 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/scribe/stringutil"
+	"github.com/grafana/scribe/tarfs"
+)
+
+var (
+	ErrorObjectNotFound     = errors.New("object not found")
+	ErrorPreconditionFailed = errors.New("object precondition failed")
+	ErrorConflict           = errors.New("state was modified concurrently too many times")
+)
+
+// Precondition is checked by an ObjectStore before an object is written.
+type Precondition struct {
+	// Version is the version that the object must have, like its ETag. If it is empty, then the object must not exist.
+	Version string
+}
+
+// An ObjectStore is a bucket in an object storage service, like S3 or Google Cloud Storage.
+type ObjectStore interface {
+	// Get returns the contents of the object and its current version. If the object does not exist, then ErrorObjectNotFound is returned.
+	Get(ctx context.Context, key string) (io.ReadCloser, string, error)
+	// Put writes the contents of the object.
+	// If the precondition is not nil and it does not match the object, then the object is not written and ErrorPreconditionFailed is returned.
+	Put(ctx context.Context, key string, r io.Reader, size int64, pre *Precondition) error
+}
+
+// objectStateAttempts is the number of times that a value is written before giving up when the state is modified concurrently.
+const objectStateAttempts = 20
+
+// ObjectState stores state in an ObjectStore.
+// Values are stored in a single JSON object, like the FilesystemState. Files and directories are stored as separate objects, where directories are stored as a '.tar.gz'.
+// Writes to the JSON object use optimistic concurrency: the object is only replaced if it was not modified since it was read, otherwise the write is attempted again.
+// This allows steps that run in parallel, even on different machines, to write values at the same time without overwriting each other's values.
+type ObjectState struct {
+	Store  ObjectStore
+	Prefix string
+
+	tmp string
+	mtx *sync.Mutex
+}
+
+func NewObjectState(store ObjectStore, prefix string) *ObjectState {
+	return &ObjectState{
+		Store:  store,
+		Prefix: strings.Trim(prefix, "/"),
+		mtx:    &sync.Mutex{},
+	}
+}
+
+func (o *ObjectState) key(elem ...string) string {
+	return path.Join(append([]string{o.Prefix}, elem...)...)
+}
+
+// tmpDir returns the local folder where files and directories from the store are downloaded.
+func (o *ObjectState) tmpDir() (string, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if o.tmp != "" {
+		return o.tmp, nil
+	}
+
+	dir, err := os.MkdirTemp("", "scribe-state-")
+	if err != nil {
+		return "", err
+	}
+
+	o.tmp = dir
+	return dir, nil
+}
+
+func (o *ObjectState) values(ctx context.Context) (map[string]stateValue, string, error) {
+	r, version, err := o.Store.Get(ctx, o.key("state.json"))
+	if err != nil {
+		if errors.Is(err, ErrorObjectNotFound) {
+			return map[string]stateValue{}, "", nil
+		}
+		return nil, "", err
+	}
+
+	defer r.Close()
+
+	state := map[string]stateValue{}
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, "", fmt.Errorf("error decoding state object '%s': %w", o.key("state.json"), err)
+	}
+
+	return state, version, nil
+}
+
+func (o *ObjectState) setValue(arg Argument, value any) error {
+	ctx := context.Background()
+	for i := 0; i < objectStateAttempts; i++ {
+		state, version, err := o.values(ctx)
+		if err != nil {
+			return err
+		}
+
+		state[arg.Key] = stateValue{
+			Argument: arg,
+			Value:    value,
+		}
+
+		body, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+
+		err = o.Store.Put(ctx, o.key("state.json"), bytes.NewReader(body), int64(len(body)), &Precondition{
+			Version: version,
+		})
+
+		if errors.Is(err, ErrorPreconditionFailed) {
+			// Another process wrote to the state since it was read, so read it again and retry after a random delay so that the writers don't keep conflicting.
+			time.Sleep(time.Duration(rand.Int63n(int64(i+1) * int64(20*time.Millisecond))))
+			continue
+		}
+
+		return err
+	}
+
+	return fmt.Errorf("%w: '%s'", ErrorConflict, arg.Key)
+}
+
+func (o *ObjectState) getValue(arg Argument) (any, error) {
+	state, _, err := o.values(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := state[arg.Key]
+	if !ok {
+		return nil, ErrorNotFound
+	}
+
+	return v.Value, nil
+}
+
+// putFile uploads the file at 'path' to the object with the key.
+func (o *ObjectState) putFile(key, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	return o.Store.Put(context.Background(), key, file, info.Size(), nil)
+}
+
+// getFile downloads the object with the key into a new file in the temporary directory.
+func (o *ObjectState) getFile(key string) (*os.File, error) {
+	r, _, err := o.Store.Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	dir, err := o.tmpDir()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp(dir, path.Base(key)+"-")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (o *ObjectState) GetString(arg Argument) (string, error) {
+	v, err := o.getValue(arg)
+	if err != nil {
+		return "", err
+	}
+
+	return v.(string), nil
+}
+
+func (o *ObjectState) SetString(arg Argument, value string) error {
+	return o.setValue(arg, value)
+}
+
+func (o *ObjectState) GetInt64(arg Argument) (int64, error) {
+	v, err := o.getValue(arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(v.(float64)), nil
+}
+
+func (o *ObjectState) SetInt64(arg Argument, value int64) error {
+	return o.setValue(arg, value)
+}
+
+func (o *ObjectState) GetFloat64(arg Argument) (float64, error) {
+	v, err := o.getValue(arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return v.(float64), nil
+}
+
+func (o *ObjectState) SetFloat64(arg Argument, value float64) error {
+	return o.setValue(arg, value)
+}
+
+func (o *ObjectState) GetBool(arg Argument) (bool, error) {
+	v, err := o.getValue(arg)
+	if err != nil {
+		return false, err
+	}
+
+	return v.(bool), nil
+}
+
+func (o *ObjectState) SetBool(arg Argument, value bool) error {
+	return o.setValue(arg, value)
+}
+
+// GetFile downloads the file from the store. The value of a file argument is the key of the object that contains it.
+func (o *ObjectState) GetFile(arg Argument) (*os.File, error) {
+	v, err := o.getValue(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.getFile(v.(string))
+}
+
+func (o *ObjectState) SetFile(arg Argument, value string) error {
+	key := o.key("files", fmt.Sprintf("%s-%s", stringutil.Slugify(arg.Key), filepath.Base(value)))
+	if err := o.putFile(key, value); err != nil {
+		return err
+	}
+
+	return o.setValue(arg, key)
+}
+
+func (o *ObjectState) SetFileReader(arg Argument, value io.Reader) error {
+	// The size of the object has to be known before it is uploaded, so the reader is copied to a temporary file first.
+	dir, err := o.tmpDir()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(dir, stringutil.Slugify(arg.Key)+"-")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if _, err := io.Copy(file, value); err != nil {
+		return err
+	}
+
+	key := o.key("files", stringutil.Slugify(arg.Key))
+	if err := o.putFile(key, file.Name()); err != nil {
+		return err
+	}
+
+	return o.setValue(arg, key)
+}
+
+// GetDirectory downloads and extracts the directory from the store.
+// Like in the FilesystemState, the value of a directory argument is the original path of the directory and the key of the '.tar.gz' object, separated by a ':'.
+// Unpackaged directories are not stored in the store, so their value is only the original path.
+func (o *ObjectState) GetDirectory(arg Argument) (fs.FS, error) {
+	v, err := o.getValue(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	p := strings.SplitN(v.(string), ":", 2)
+	if len(p) == 1 {
+		return os.DirFS(p[0]), nil
+	}
+
+	file, err := o.getFile(p[1])
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	dir, err := o.tmpDir()
+	if err != nil {
+		return nil, err
+	}
+
+	destination := filepath.Join(dir, stringutil.Slugify(arg.Key), stringutil.Random(8))
+	if err := tarfs.Untar(destination, file); err != nil {
+		return nil, err
+	}
+
+	return os.DirFS(destination), nil
+}
+
+// GetDirectoryString retrieves the original directory path.
+func (o *ObjectState) GetDirectoryString(arg Argument) (string, error) {
+	v, err := o.getValue(arg)
+	if err != nil {
+		return "", err
+	}
+
+	p := strings.SplitN(v.(string), ":", 2)
+	return p[0], nil
+}
+
+func (o *ObjectState) setDirectory(arg Argument, value string) error {
+	dir, err := o.tmpDir()
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.tar.gz", stringutil.Slugify(arg.Key), stringutil.Random(8))
+	file, err := tarfs.WriteFile(filepath.Join(dir, name), os.DirFS(value))
+	if err != nil {
+		return fmt.Errorf("error creating tar.gz for directory state: %w", err)
+	}
+
+	// WriteFile closes the file after it's written.
+	defer os.Remove(file.Name())
+
+	key := o.key("directories", name)
+	if err := o.putFile(key, file.Name()); err != nil {
+		return err
+	}
+
+	return o.setValue(arg, strings.Join([]string{value, key}, ":"))
+}
+
+func (o *ObjectState) SetDirectory(arg Argument, value string) error {
+	if arg.Type == ArgumentTypeFS {
+		return o.setDirectory(arg, value)
+	}
+
+	info, err := os.Stat(value)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("directory '%s' does not exist", value)
+	}
+
+	return o.setValue(arg, value)
+}
+
+func (o *ObjectState) Exists(arg Argument) (bool, error) {
+	_, err := o.getValue(arg)
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, ErrorNotFound) {
+		return false, nil
+	}
+
+	return false, err
+}
@@ -0,0 +1,146 @@
+package state_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/scribe/state"
+	"github.com/grafana/scribe/swfs"
+)
+
+// testObjectState tests the ObjectState with a store created by 'newState'. Every call to 'newState' should return a new state that uses the same store.
+// The 'objects' function returns the keys of every object in the store.
+func testObjectState(t *testing.T, newState func(t *testing.T) *state.ObjectState, objects func() []string) {
+	t.Run("Values should be stored in a JSON object", func(t *testing.T) {
+		var (
+			s = newState(t)
+
+			str = state.NewStringArgument("string")
+			i   = state.NewInt64Argument("int")
+			b   = state.NewBoolArgument("bool")
+		)
+
+		if exists, err := s.Exists(str); err != nil || exists {
+			t.Fatalf("Expected argument to not exist, but got '%t', '%v'", exists, err)
+		}
+
+		if err := s.SetString(str, "value"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetInt64(i, 12); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetBool(b, true); err != nil {
+			t.Fatal(err)
+		}
+
+		if v, err := s.GetString(str); err != nil || v != "value" {
+			t.Fatalf("Expected 'value' but got '%s', '%v'", v, err)
+		}
+		if v, err := s.GetInt64(i); err != nil || v != 12 {
+			t.Fatalf("Expected '12' but got '%d', '%v'", v, err)
+		}
+		if v, err := s.GetBool(b); err != nil || !v {
+			t.Fatalf("Expected 'true' but got '%t', '%v'", v, err)
+		}
+
+		found := false
+		for _, v := range objects() {
+			if strings.HasSuffix(v, "builds/1/state.json") {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("Expected state to be stored in 'builds/1/state.json' but the objects are '%v'", objects())
+		}
+	})
+
+	t.Run("Files and directories should be stored as objects", func(t *testing.T) {
+		var (
+			s    = newState(t)
+			file = state.NewFileArgument("file")
+			dir  = state.NewDirectoryArgument("dir")
+		)
+
+		if err := s.SetFileReader(file, strings.NewReader("file contents")); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := s.GetFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "file contents" {
+			t.Fatalf("Expected 'file contents' but got '%s'", string(b))
+		}
+
+		src := filepath.Join("..", "tarfs", "testdir")
+		if err := s.SetDirectory(dir, src); err != nil {
+			t.Fatal(err)
+		}
+
+		d, err := s.GetDirectory(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		equal, err := swfs.Equal(os.DirFS(src), d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equal {
+			t.Fatal("Expected the directory from the state to equal the original directory")
+		}
+
+		if v, err := s.GetDirectoryString(dir); err != nil || v != src {
+			t.Fatalf("Expected '%s' but got '%s', '%v'", src, v, err)
+		}
+	})
+
+	t.Run("Concurrent writes should not overwrite each other", func(t *testing.T) {
+		var (
+			wg = &sync.WaitGroup{}
+			n  = 20
+		)
+
+		errs := make(chan error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			// Every write uses its own state to simulate steps running in different processes.
+			go func(s *state.ObjectState, i int) {
+				defer wg.Done()
+				errs <- s.SetInt64(state.NewInt64Argument(fmt.Sprintf("arg-%d", i)), int64(i))
+			}(newState(t), i)
+		}
+
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		s := newState(t)
+		for i := 0; i < n; i++ {
+			v, err := s.GetInt64(state.NewInt64Argument(fmt.Sprintf("arg-%d", i)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != int64(i) {
+				t.Fatalf("Expected '%d' but got '%d'", i, v)
+			}
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package state
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrorSecretNotEncrypted = errors.New("secret in state is not encrypted")
+	ErrorDecryptSecret      = errors.New("error decrypting secret")
+	ErrorStateKeyRequired   = errors.New("secret in state is encrypted, but no state key was provided. Provide the key with the 'SCRIBE_STATE_KEY' environment variable or the '-state-key-file' argument")
+)
+
+// RedactedValue replaces every secret value that is redacted by the Redactor.
+const RedactedValue = "[REDACTED]"
+
+// Redactor stores the values of secrets that are read from or written to the state and removes them from text.
+// It is also a logrus hook, so adding it to a logger removes secrets from every log message and field, including the stdout and stderr of steps.
+type Redactor struct {
+	values map[string]bool
+	mtx    *sync.RWMutex
+}
+
+func NewRedactor() *Redactor {
+	return &Redactor{
+		values: map[string]bool{},
+		mtx:    &sync.RWMutex{},
+	}
+}
+
+// Add adds the values of secrets to the Redactor.
+// Every line of a value with multiple lines is also added, as logs are typically written line by line.
+func (r *Redactor) Add(values ...string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, v := range values {
+		for _, line := range append(strings.Split(v, "\n"), v) {
+			if line = strings.TrimSpace(line); line != "" {
+				r.values[line] = true
+			}
+		}
+	}
+}
+
+// Redact replaces every secret in the string with RedactedValue.
+func (r *Redactor) Redact(s string) string {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if len(r.values) == 0 {
+		return s
+	}
+
+	values := make([]string, 0, len(r.values))
+	for v := range r.values {
+		values = append(values, v)
+	}
+
+	// Longer values are replaced first so that a secret which contains another secret is replaced completely.
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, RedactedValue)
+	}
+
+	return s
+}
+
+func (r *Redactor) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire redacts secrets from the message and fields of the log entry before it is written.
+func (r *Redactor) Fire(entry *logrus.Entry) error {
+	entry.Message = r.Redact(entry.Message)
+
+	for k, v := range entry.Data {
+		switch value := v.(type) {
+		case string:
+			entry.Data[k] = r.Redact(value)
+		case error:
+			entry.Data[k] = errors.New(r.Redact(value.Error()))
+		}
+	}
+
+	return nil
+}
+
+// encryptedPrefix is prepended to encrypted secrets in the state so that they can be distinguished from plaintext values.
+const encryptedPrefix = "encrypted:"
+
+// IsEncrypted returns true if the value was encrypted by an EncryptedStateHandler.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// EncryptedStateHandler encrypts the values of secret arguments before they are stored in the StateHandler, and decrypts them when they are read.
+// Values are encrypted using AES-256-GCM with a key derived from the key provided, so every process that uses the same state must use the same key.
+type EncryptedStateHandler struct {
+	StateHandler
+	aead cipher.AEAD
+}
+
+func NewEncryptedStateHandler(handler StateHandler, key []byte) (*EncryptedStateHandler, error) {
+	if len(key) == 0 {
+		return nil, errors.New("an empty key can not be used to encrypt secrets")
+	}
+
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedStateHandler{
+		StateHandler: handler,
+		aead:         aead,
+	}, nil
+}
+
+func (e *EncryptedStateHandler) encrypt(value string) (string, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := e.aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (e *EncryptedStateHandler) decrypt(arg Argument, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("%w: '%s'", ErrorSecretNotEncrypted, arg.Key)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(b) < e.aead.NonceSize() {
+		return "", fmt.Errorf("%w: '%s' is malformed", ErrorDecryptSecret, arg.Key)
+	}
+
+	n := e.aead.NonceSize()
+	plain, err := e.aead.Open(nil, b[:n], b[n:], nil)
+	if err != nil {
+		// The error from the cipher is not wrapped; it is typically caused by a different key being used to encrypt the value.
+		return "", fmt.Errorf("%w: '%s' was likely encrypted with a different key", ErrorDecryptSecret, arg.Key)
+	}
+
+	return string(plain), nil
+}
+
+func (e *EncryptedStateHandler) GetString(arg Argument) (string, error) {
+	value, err := e.StateHandler.GetString(arg)
+	if err != nil || arg.Type != ArgumentTypeSecret {
+		return value, err
+	}
+
+	return e.decrypt(arg, value)
+}
+
+func (e *EncryptedStateHandler) SetString(arg Argument, value string) error {
+	if arg.Type != ArgumentTypeSecret {
+		return e.StateHandler.SetString(arg, value)
+	}
+
+	encrypted, err := e.encrypt(value)
+	if err != nil {
+		return err
+	}
+
+	return e.StateHandler.SetString(arg, encrypted)
+}
@@ -0,0 +1,173 @@
+package state_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/scribe/state"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedactor(t *testing.T) {
+	t.Run("Secrets should be removed from log messages and fields", func(t *testing.T) {
+		var (
+			buf      = &bytes.Buffer{}
+			log      = logrus.New()
+			redactor = state.NewRedactor()
+		)
+
+		log.SetOutput(buf)
+		log.AddHook(redactor)
+		redactor.Add("hunter2", "multi\nline")
+
+		log.WithField("password", "hunter2").WithError(errors.New("invalid password hunter2")).Infoln("logging in with hunter2")
+		log.Infoln("the second line of the secret is 'line'")
+
+		out := buf.String()
+		if strings.Contains(out, "hunter2") || strings.Contains(out, "'line'") {
+			t.Fatalf("Expected secrets to be redacted, but got '%s'", out)
+		}
+		if !strings.Contains(out, state.RedactedValue) {
+			t.Fatalf("Expected output to contain '%s', but got '%s'", state.RedactedValue, out)
+		}
+	})
+
+	t.Run("Secrets read from the state should be added to the redactor", func(t *testing.T) {
+		handler, err := state.NewFilesystemState(filepath.Join(t.TempDir(), "state.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s := &state.State{
+			Handler:  handler,
+			Log:      logrus.New(),
+			Redactor: state.NewRedactor(),
+		}
+
+		if err := s.SetString(state.NewSecretArgument("secret"), "hunter2"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetString(state.NewStringArgument("string"), "not-a-secret"); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "[REDACTED] not-a-secret"
+		if v := s.Redactor.Redact("hunter2 not-a-secret"); v != expected {
+			t.Fatalf("Expected '%s' but got '%s'", expected, v)
+		}
+	})
+}
+
+func TestEncryptedStateHandler(t *testing.T) {
+	handler, err := state.NewFilesystemState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := state.NewEncryptedStateHandler(handler, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		secret = state.NewSecretArgument("secret")
+		str    = state.NewStringArgument("string")
+	)
+
+	if err := s.SetString(secret, "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetString(str, "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Secrets should be encrypted in the underlying state", func(t *testing.T) {
+		v, err := handler.GetString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(v, "hunter2") {
+			t.Fatalf("Expected secret to be encrypted but got '%s'", v)
+		}
+
+		if v, err := handler.GetString(str); err != nil || v != "value" {
+			t.Fatalf("Expected other values to not be encrypted, but got '%s', '%v'", v, err)
+		}
+	})
+
+	t.Run("Secrets should be decrypted when they are read", func(t *testing.T) {
+		if v, err := s.GetString(secret); err != nil || v != "hunter2" {
+			t.Fatalf("Expected 'hunter2' but got '%s', '%v'", v, err)
+		}
+	})
+
+	t.Run("Secrets encrypted with a different key should return an error", func(t *testing.T) {
+		other, err := state.NewEncryptedStateHandler(handler, []byte("other key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := other.GetString(secret); !errors.Is(err, state.ErrorDecryptSecret) {
+			t.Fatalf("Expected error '%v' but got '%v'", state.ErrorDecryptSecret, err)
+		}
+	})
+
+	t.Run("Secrets that are not encrypted should return an error", func(t *testing.T) {
+		if err := handler.SetString(secret, "plaintext"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.GetString(secret); !errors.Is(err, state.ErrorSecretNotEncrypted) {
+			t.Fatalf("Expected error '%v' but got '%v'", state.ErrorSecretNotEncrypted, err)
+		}
+	})
+}
+
+func TestStateEncryptedSecretWithoutKey(t *testing.T) {
+	handler, err := state.NewFilesystemState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := state.NewEncryptedStateHandler(handler, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := state.NewSecretArgument("secret")
+	if err := encrypted.SetString(secret, "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &state.State{
+		Handler:  handler,
+		Log:      logrus.New(),
+		Redactor: state.NewRedactor(),
+	}
+
+	if _, err := s.GetString(secret); !errors.Is(err, state.ErrorStateKeyRequired) {
+		t.Fatalf("Expected error '%v' but got '%v'", state.ErrorStateKeyRequired, err)
+	}
+}
+
+func TestStdinReaderSecret(t *testing.T) {
+	var (
+		out = &bytes.Buffer{}
+		r   = state.NewStdinReader(strings.NewReader("hunter2\n"), out)
+	)
+
+	v, err := r.GetString(state.NewSecretArgument("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "hunter2" {
+		t.Fatalf("Expected 'hunter2' but got '%s'", v)
+	}
+
+	if strings.Contains(out.String(), "hunter2") {
+		t.Fatalf("Expected the prompt to not print the secret, but got '%s'", out.String())
+	}
+}
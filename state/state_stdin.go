@@ -10,12 +10,15 @@ import (
 )
 
 var argTypeExamples = map[ArgumentType]string{
-	ArgumentTypeString:  "some-value",
-	ArgumentTypeInt64:   "13400",
-	ArgumentTypeFloat64: "13.4",
-	ArgumentTypeSecret:  "some-value",
-	ArgumentTypeFile:    "./path/to/file.txt",
-	ArgumentTypeFS:      "./path/to/folder",
+	ArgumentTypeString:      "some-value",
+	ArgumentTypeInt64:       "13400",
+	ArgumentTypeFloat64:     "13.4",
+	ArgumentTypeSecret:      "some-value",
+	ArgumentTypeFile:        "./path/to/file.txt",
+	ArgumentTypeFS:          "./path/to/folder",
+	ArgumentTypeStringSlice: "a,b,c",
+	ArgumentTypeStringMap:   "key=value,key2=value2",
+	ArgumentTypeJSON:        `{"key": "value"}`,
 }
 
 type StdinReader struct {
@@ -32,15 +35,34 @@ func NewStdinReader(in io.Reader, out io.Writer) *StdinReader {
 
 func (s *StdinReader) Get(arg Argument) (string, error) {
 	fmt.Fprintf(s.out, "Argument '%[1]s' requested but not found. Please provide a value for '%[1]s' of type '%s'. Example: '%s': ", arg.Key, arg.Type.String(), argTypeExamples[arg.Type])
+
+	// Secrets should not be visible while they are typed, so the terminal stops echoing the input until the value is read.
+	restore := func() {}
+	if f, ok := s.in.(*os.File); ok && arg.Type == ArgumentTypeSecret {
+		if r, ok := disableEcho(f); ok {
+			restore = func() {
+				r()
+				// The newline that was typed was not echoed either.
+				fmt.Fprintln(s.out)
+			}
+		}
+	}
+
 	// Prompt for the value via stdin since it was not found
 	scanner := bufio.NewScanner(s.in)
 	scanner.Scan()
+	restore()
 
 	if err := scanner.Err(); err != nil {
 		return "", err
 	}
 
 	value := scanner.Text()
+	if arg.Type == ArgumentTypeSecret {
+		fmt.Fprintf(s.out, "In the future, you can provide this value with the '-arg=%s=...' argument\n", arg.Key)
+		return value, nil
+	}
+
 	fmt.Fprintf(s.out, "In the future, you can provide this value with the '-arg=%s=%s' argument\n", arg.Key, value)
 	return value, nil
 }
@@ -0,0 +1,121 @@
+package scribe_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/scribe"
+	"github.com/grafana/scribe/args"
+	"github.com/grafana/scribe/state"
+)
+
+func TestGetState(t *testing.T) {
+	t.Run("Filesystem states should be namespaced by the build ID", func(t *testing.T) {
+		var (
+			dir = t.TempDir()
+			arg = state.NewStringArgument("string")
+		)
+
+		for _, v := range []string{"1", "2"} {
+			s, err := scribe.GetState("file://"+filepath.Join(dir, "state.json"), logger(), &args.PipelineArgs{BuildID: v, BuildIDProvided: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if exists, err := s.Handler.Exists(arg); err != nil || exists {
+				t.Fatalf("Expected argument to not exist in build '%s', but got '%t', '%v'", v, exists, err)
+			}
+
+			if err := s.SetString(arg, v); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, v, "state.json")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	t.Run("A filesystem state in a directory should use the build ID as the file name", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := scribe.GetState("file://"+dir, logger(), &args.PipelineArgs{BuildID: "1", BuildIDProvided: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.SetString(state.NewStringArgument("string"), "value"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "1.json")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Filesystem states should not be namespaced by a build ID that was not provided", func(t *testing.T) {
+		var (
+			dir  = t.TempDir()
+			path = filepath.Join(dir, "state.json")
+			arg  = state.NewStringArgument("string")
+		)
+
+		for _, v := range []string{"1", "2"} {
+			s, err := scribe.GetState("file://"+path, logger(), &args.PipelineArgs{BuildID: v})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The value from the first run should still be in the state in the second run.
+			if v == "2" {
+				if value, err := s.GetString(arg); err != nil || value != "1" {
+					t.Fatalf("Expected '1' from the previous run but got '%s', '%v'", value, err)
+				}
+			}
+
+			if err := s.SetString(arg, v); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Arguments should be read from the -arg flags, then the environment, then the argument file", func(t *testing.T) {
+		var (
+			dir  = t.TempDir()
+			file = filepath.Join(dir, "args.yaml")
+		)
+
+		if err := os.WriteFile(file, []byte("a: file\nb: file\nc: file\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Setenv("SCRIBE_ARG_A", "env")
+		t.Setenv("SCRIBE_ARG_B", "env")
+
+		s, err := scribe.GetState("file://"+dir, logger(), &args.PipelineArgs{
+			BuildID:      "1",
+			ArgMap:       args.ArgMap{"a": "arg"},
+			ArgEnvPrefix: "SCRIBE_ARG_",
+			ArgFile:      file,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[string]string{
+			"a": "arg",
+			"b": "env",
+			"c": "file",
+		}
+
+		for k, v := range expected {
+			if value, err := s.GetString(state.NewStringArgument(k)); err != nil || value != v {
+				t.Fatalf("Expected '%s' for '%s' but got '%s', '%v'", v, k, value, err)
+			}
+		}
+	})
+}
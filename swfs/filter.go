@@ -0,0 +1,71 @@
+package swfs
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// filterFS is an fs.FS that only exposes the listed paths (and the directories that lead to them) of the embedded filesystem.
+// If a listed path is a directory, then everything inside of it is exposed as well.
+type filterFS struct {
+	fs.FS
+	paths []string
+}
+
+// Filter returns a filesystem that only contains the provided paths from dir.
+// Paths must be valid fs.FS paths, like those returned from fs.Glob.
+// This is useful for packaging a subset of a directory with functions that accept an fs.FS, like tarfs.Write or HashFS.
+func Filter(dir fs.FS, paths ...string) fs.FS {
+	return &filterFS{
+		FS:    dir,
+		paths: paths,
+	}
+}
+
+func (f *filterFS) allowed(name string) bool {
+	if name == "." {
+		return true
+	}
+
+	for _, p := range f.paths {
+		// The path is listed, is inside of a listed directory, or is a parent directory of a listed path.
+		if name == p || strings.HasPrefix(name, p+"/") || strings.HasPrefix(p, name+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *filterFS) Open(name string) (fs.File, error) {
+	if !f.allowed(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return f.FS.Open(name)
+}
+
+func (f *filterFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !f.allowed(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries, err := fs.ReadDir(f.FS, name)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []fs.DirEntry{}
+	for _, v := range entries {
+		path := v.Name()
+		if name != "." {
+			path = name + "/" + v.Name()
+		}
+
+		if f.allowed(path) {
+			filtered = append(filtered, v)
+		}
+	}
+
+	return filtered, nil
+}
@@ -0,0 +1,35 @@
+package swfs_test
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/grafana/scribe/swfs"
+	"golang.org/x/exp/slices"
+)
+
+func TestFilter(t *testing.T) {
+	dir := swfs.Filter(os.DirFS("testdata"), "a.json", "c")
+
+	paths := []string{}
+	if err := fs.WalkDir(dir, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{".", "a.json", "c", "c/c.json"}
+	if !slices.Equal(paths, expected) {
+		t.Fatalf("Unexpected paths in filtered filesystem. Expected '%v', received '%v'", expected, paths)
+	}
+
+	if _, err := dir.Open("b.json"); err == nil {
+		t.Fatal("Expected error opening a file that was filtered out")
+	}
+}
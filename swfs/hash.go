@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"sort"
 )
 
 func HashDirectory(dir string) ([]byte, error) {
@@ -46,11 +47,18 @@ func HashFS(dir fs.FS) ([]byte, error) {
 		return nil, err
 	}
 
+	// Sort the paths so that the resulting hash does not depend on map iteration order.
+	paths := make([]string, 0, len(hashes))
+	for k := range hashes {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+
 	// For each hashed file, add it to one big hash
 	hash := sha256.New()
-	for k, b := range hashes {
-		hash.Sum([]byte(k))
-		hash.Sum(b)
+	for _, k := range paths {
+		hash.Write([]byte(k))
+		hash.Write(hashes[k])
 	}
 
 	return hash.Sum(nil), nil
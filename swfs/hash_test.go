@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -38,13 +39,24 @@ func TestEncodeDir(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	files := []struct {
+		path string
+		hash string
+	}{
+		{"a.json", "e8f1fae1d192acff9666ffb429757fb60cb92dfa39e3ac074777fd01e1bfabbf"},
+		{"b.json", "497ec934da3f4dc5708e4be58a11f72224b23127b8b402256c114a892ae2aba2"},
+		{"c/c.json", "bbd82e48900b9f9bbe1a00eca6a9ec646eb7126a442dc60b6dd0255de6abd48c"},
+	}
+
 	hash := sha256.New()
-	hash.Sum([]byte("a.json"))
-	hash.Sum([]byte("e8f1fae1d192acff9666ffb429757fb60cb92dfa39e3ac074777fd01e1bfabbf"))
-	hash.Sum([]byte("b.json"))
-	hash.Sum([]byte("497ec934da3f4dc5708e4be58a11f72224b23127b8b402256c114a892ae2aba2"))
-	hash.Sum([]byte("c/c.json"))
-	hash.Sum([]byte("bbd82e48900b9f9bbe1a00eca6a9ec646eb7126a442dc60b6dd0255de6abd48c"))
+	for _, v := range files {
+		b, err := hex.DecodeString(v.hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash.Write([]byte(v.path))
+		hash.Write(b)
+	}
 
 	expect := hash.Sum(nil)
 
@@ -52,3 +64,28 @@ func TestEncodeDir(t *testing.T) {
 		t.Fatalf("Unexpected result from HashDirectory:\nExpected: '%x'\nReceived: '%x'", expect, b)
 	}
 }
+
+func TestEncodeDirChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := swfs.HashDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := swfs.HashDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(a, b) {
+		t.Fatalf("Expected HashDirectory to return a different hash after a file was changed, but received '%x' both times", a)
+	}
+}
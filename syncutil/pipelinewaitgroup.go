@@ -19,8 +19,7 @@ func (w *PipelineWaitGroup) Add(f pipeline.Pipeline, walker pipeline.Walker, wf
 }
 
 // Wait runs all provided functions (via Add(...)) and runs them in parallel and waits for them to finish.
-// If they are not all finished before the provided timeout (via NewPipelineWaitGroup), then an error is returned.
-// If any functions return an error, the first error encountered is returned.
+// If any functions return an error, then the error is handled using the FailurePolicy (see NewPipelineWaitGroupWithPolicy).
 func (w *PipelineWaitGroup) Wait(ctx context.Context) error {
 	return w.wg.Wait(ctx)
 }
@@ -30,3 +29,10 @@ func NewPipelineWaitGroup() *PipelineWaitGroup {
 		wg: NewWaitGroup(),
 	}
 }
+
+// NewPipelineWaitGroupWithPolicy creates a PipelineWaitGroup that handles errors using the FailurePolicy.
+func NewPipelineWaitGroupWithPolicy(policy FailurePolicy) *PipelineWaitGroup {
+	return &PipelineWaitGroup{
+		wg: NewWaitGroupWithPolicy(policy),
+	}
+}
@@ -0,0 +1,129 @@
+package syncutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/scribe/pipeline"
+	"github.com/sirupsen/logrus"
+)
+
+// Scheduler limits which steps run at the same time, across every StepWaitGroup that uses it.
+// A step is only started when the total weight of the running steps, including it, is not more than the capacity, and when none of its locks are held by a running step (see pipeline.Resources).
+// A nil *Scheduler starts every step right away.
+type Scheduler struct {
+	capacity int
+
+	mutex sync.Mutex
+	used  int
+	locks map[string]bool
+	// released is closed and replaced every time a step is done, to wake up the steps that are waiting to start.
+	released chan struct{}
+}
+
+// NewScheduler creates a Scheduler that runs steps with a total weight of up to 'capacity' at the same time. If 'capacity' is 0, then only the steps' locks are honored.
+func NewScheduler(capacity int) *Scheduler {
+	return &Scheduler{
+		capacity: capacity,
+		locks:    map[string]bool{},
+		released: make(chan struct{}),
+	}
+}
+
+// weight returns the weight of the step, which is never more than the capacity so that heavy steps can still run on their own.
+func (s *Scheduler) weight(step pipeline.Step) int {
+	w := step.Weight()
+	if s.capacity != 0 && w > s.capacity {
+		return s.capacity
+	}
+
+	return w
+}
+
+// tryAcquire reserves the step's weight and locks if they are available. If they are not, then it returns a channel that is closed when another step is done.
+func (s *Scheduler) tryAcquire(step pipeline.Step) (bool, <-chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w := s.weight(step)
+	if s.capacity != 0 && s.used+w > s.capacity {
+		return false, s.released
+	}
+
+	for _, v := range step.Resources.Locks {
+		if s.locks[v] {
+			return false, s.released
+		}
+	}
+
+	s.used += w
+	for _, v := range step.Resources.Locks {
+		s.locks[v] = true
+	}
+
+	return true, nil
+}
+
+func (s *Scheduler) release(step pipeline.Step) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.used -= s.weight(step)
+	for _, v := range step.Resources.Locks {
+		delete(s.locks, v)
+	}
+
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+// Acquire waits until the step can start, and returns a function that must be called once the step is done.
+// Background steps run for as long as the pipeline does, so they are started right away and do not count towards the capacity.
+// If the context is cancelled before the step can start, then the context's error is returned.
+// The step is logged with the 'queued' status if it has to wait, and with the 'started' status when it starts.
+func (s *Scheduler) Acquire(ctx context.Context, step pipeline.Step, log logrus.FieldLogger) (func(), error) {
+	if s == nil || step.IsBackground() {
+		return func() {}, nil
+	}
+
+	if log == nil {
+		log = logrus.StandardLogger()
+	}
+
+	var (
+		queued bool
+		start  = time.Now()
+		fields = logrus.Fields{
+			"weight": s.weight(step),
+			"locks":  step.Resources.Locks,
+		}
+	)
+
+	for {
+		ok, released := s.tryAcquire(step)
+		if ok {
+			break
+		}
+
+		if !queued {
+			queued = true
+			log.WithFields(fields).WithField("status", "queued").Infoln("waiting for other steps to finish before starting")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-released:
+		}
+	}
+
+	if queued {
+		log.WithFields(fields).WithField("status", "started").Infoln(fmt.Sprintf("started after waiting for %s", time.Since(start).Round(time.Millisecond)))
+	} else {
+		log.WithFields(fields).WithField("status", "started").Debugln("started")
+	}
+
+	return func() { s.release(step) }, nil
+}
@@ -0,0 +1,128 @@
+package syncutil_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/syncutil"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+// concurrency records the most steps that ran at the same time.
+type concurrency struct {
+	mutex   sync.Mutex
+	running int
+	max     int
+}
+
+func (c *concurrency) step(name string) pipeline.Step {
+	return pipeline.NamedStep(name, func(context.Context, pipeline.ActionOpts) error {
+		c.mutex.Lock()
+		c.running++
+		if c.running > c.max {
+			c.max = c.running
+		}
+		c.mutex.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		c.mutex.Lock()
+		c.running--
+		c.mutex.Unlock()
+		return nil
+	})
+}
+
+func runScheduled(t *testing.T, s *syncutil.Scheduler, steps ...pipeline.Step) {
+	t.Helper()
+
+	wg := syncutil.NewStepWaitGroup().WithScheduler(s)
+	for _, v := range steps {
+		wg.Add(v, pipeline.ActionOpts{
+			Logger: logrus.New(),
+		})
+	}
+
+	if err := wg.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("It should not run more steps than the capacity at the same time", func(t *testing.T) {
+		c := &concurrency{}
+		runScheduled(t, syncutil.NewScheduler(2), c.step("a"), c.step("b"), c.step("c"), c.step("d"))
+
+		if c.max != 2 {
+			t.Fatalf("Expected at most 2 steps to run at the same time but got %d", c.max)
+		}
+	})
+
+	t.Run("It should count the weight of each step", func(t *testing.T) {
+		c := &concurrency{}
+		runScheduled(t, syncutil.NewScheduler(4), c.step("a").WithCPU(3), c.step("b").WithCPU(3), c.step("c").WithCPU(3))
+
+		if c.max != 1 {
+			t.Fatalf("Expected 1 step to run at a time but got %d", c.max)
+		}
+	})
+
+	t.Run("It should run steps that are heavier than the capacity on their own", func(t *testing.T) {
+		c := &concurrency{}
+		runScheduled(t, syncutil.NewScheduler(2), c.step("a").WithCPU(8), c.step("b"))
+
+		if c.max != 1 {
+			t.Fatalf("Expected 1 step to run at a time but got %d", c.max)
+		}
+	})
+
+	t.Run("It should not run steps that share a lock at the same time", func(t *testing.T) {
+		c := &concurrency{}
+		runScheduled(t, syncutil.NewScheduler(0), c.step("a").WithLock("docker"), c.step("b").WithLock("docker", "network"), c.step("c").WithLock("network", "docker"))
+
+		if c.max != 1 {
+			t.Fatalf("Expected 1 step to run at a time but got %d", c.max)
+		}
+	})
+
+	t.Run("It should not limit background steps", func(t *testing.T) {
+		var (
+			s       = syncutil.NewScheduler(1)
+			running = pipeline.NamedStep("running", nil)
+		)
+
+		release, err := s.Acquire(context.Background(), running, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer release()
+
+		background := pipeline.NamedStep("background", nil)
+		background.Type = pipeline.StepTypeBackground
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := s.Acquire(ctx, background, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("It should stop waiting when the context is cancelled", func(t *testing.T) {
+		s := syncutil.NewScheduler(1)
+		release, err := s.Acquire(context.Background(), pipeline.NamedStep("a", nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = s.Acquire(ctx, pipeline.NamedStep("b", nil), nil)
+		testutil.EnsureError(t, err, context.DeadlineExceeded)
+	})
+}
@@ -2,25 +2,49 @@ package syncutil
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/grafana/scribe/pipeline"
 )
 
 // StepWaitGroup is a wrapper around a WaitGroup that runs the actions of a list of steps, handles errors, and watches for context cancellation.
 type StepWaitGroup struct {
-	wg *WaitGroup
+	wg        *WaitGroup
+	scheduler *Scheduler
 }
 
 // Add adds a new Action to the waitgroup. The provided function will be run in parallel with all other added functions.
+// The step's Timeout and Retry are enforced when the action runs. If the waitgroup has a Scheduler, then the step waits for it before it starts.
 func (w *StepWaitGroup) Add(f pipeline.Step, opts pipeline.ActionOpts) {
 	w.wg.Add(func(ctx context.Context) error {
-		return f.Action(ctx, opts)
+		release, err := w.scheduler.Acquire(ctx, f, opts.Logger)
+		if err != nil {
+			return err
+		}
+
+		stopped, err := runStep(ctx, f, opts)
+
+		// The step's resources are only released once its action has returned, even if the step already failed because the action ignored its context.
+		go func() {
+			<-stopped
+			release()
+		}()
+
+		return err
 	})
 }
 
+// WithScheduler sets the Scheduler that decides when each step in the waitgroup can start.
+// The same Scheduler should be used by every StepWaitGroup that runs steps on the same machine.
+func (w *StepWaitGroup) WithScheduler(s *Scheduler) *StepWaitGroup {
+	w.scheduler = s
+	return w
+}
+
 // Wait runs all provided functions (via Add(...)) and runs them in parallel and waits for them to finish.
-// If they are not all finished before the provided timeout (via NewStepWaitGroup), then an error is returned.
-// If any functions return an error, the first error encountered is returned.
+// If any functions return an error, then the error is handled using the FailurePolicy (see NewStepWaitGroupWithPolicy).
 func (w *StepWaitGroup) Wait(ctx context.Context) error {
 	return w.wg.Wait(ctx)
 }
@@ -30,3 +54,114 @@ func NewStepWaitGroup() *StepWaitGroup {
 		wg: NewWaitGroup(),
 	}
 }
+
+// NewStepWaitGroupWithPolicy creates a StepWaitGroup that handles errors using the FailurePolicy.
+func NewStepWaitGroupWithPolicy(policy FailurePolicy) *StepWaitGroup {
+	return &StepWaitGroup{
+		wg: NewWaitGroupWithPolicy(policy),
+	}
+}
+
+// ActionStopTimeout is how long a step waits for its action to return after the action's context is cancelled, like when the step's timeout expires.
+// If the action does not return in time, then the step fails without being retried, as the action ignored its context and might still be running.
+var ActionStopTimeout = 30 * time.Second
+
+// RunStep runs the action of the step, cancelling it if it takes longer than the step's Timeout, and running it again if it fails and the step has retries left.
+// An attempt is only retried once its action has returned, so that attempts never run at the same time.
+// The error from the last attempt is returned, unless the step allows failures (see Step.AllowFailure), in which case the error is logged and nil is returned.
+func RunStep(ctx context.Context, step pipeline.Step, opts pipeline.ActionOpts) error {
+	_, err := runStep(ctx, step, opts)
+	return err
+}
+
+// runStep is RunStep, but it also returns a channel that is closed once the action of the last attempt has returned.
+// If the action ignored its context, then the channel is still open when runStep returns.
+func runStep(ctx context.Context, step pipeline.Step, opts pipeline.ActionOpts) (<-chan struct{}, error) {
+	stopped, err := runAttempts(ctx, step, opts)
+	if err != nil && step.FailureAllowed {
+		if opts.Logger != nil {
+			opts.Logger.WithError(err).Warnln("step failed, but its failure is allowed")
+		}
+		return stopped, nil
+	}
+
+	return stopped, err
+}
+
+func runAttempts(ctx context.Context, step pipeline.Step, opts pipeline.ActionOpts) (<-chan struct{}, error) {
+	backoff := step.Retry.Backoff
+	for attempt := 1; ; attempt++ {
+		stopped, err := runAttempt(ctx, step, opts)
+		if err == nil || attempt > step.Retry.Retries || ctx.Err() != nil {
+			return stopped, err
+		}
+
+		select {
+		case <-stopped:
+		default:
+			if opts.Logger != nil {
+				opts.Logger.WithError(err).Warnf("attempt %d of %d failed, but its action did not stop after %s; not retrying", attempt, step.Retry.Retries+1, ActionStopTimeout)
+			}
+			return stopped, err
+		}
+
+		if opts.Logger != nil {
+			opts.Logger.WithError(err).Warnf("attempt %d of %d failed; retrying in %s", attempt, step.Retry.Retries+1, backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return stopped, err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// runAttempt runs the action of the step once. If the step has a timeout, then the context provided to the action is cancelled when it expires.
+// When the context is cancelled, like when a step that runs in parallel fails with the FailFast policy, runAttempt waits up to ActionStopTimeout for the action to return.
+// The returned channel is closed once the action has returned. Actions that do not stop when their context is cancelled are left running, but the step still fails.
+func runAttempt(ctx context.Context, step pipeline.Step, opts pipeline.ActionOpts) (<-chan struct{}, error) {
+	var (
+		actx   context.Context
+		cancel context.CancelFunc
+	)
+
+	if step.Timeout != 0 {
+		actx, cancel = context.WithTimeout(ctx, step.Timeout)
+	} else {
+		actx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		errChan = make(chan error, 1)
+		stopped = make(chan struct{})
+	)
+
+	go func() {
+		defer close(stopped)
+		errChan <- step.Action(actx, opts)
+	}()
+
+	var err error
+	select {
+	case err = <-errChan:
+	case <-actx.Done():
+		select {
+		case err = <-errChan:
+		case <-time.After(ActionStopTimeout):
+			err = actx.Err()
+		}
+	}
+
+	if err != nil && ctx.Err() == nil && actx.Err() == context.DeadlineExceeded {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return stopped, fmt.Errorf("%w (%s)", pipeline.ErrorStepTimeout, step.Timeout)
+		}
+		return stopped, fmt.Errorf("%w (%s): %s", pipeline.ErrorStepTimeout, step.Timeout, err)
+	}
+
+	return stopped, err
+}
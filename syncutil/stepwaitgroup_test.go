@@ -0,0 +1,136 @@
+package syncutil_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/syncutil"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestStepWaitGroup(t *testing.T) {
+	opts := pipeline.ActionOpts{
+		Logger: logrus.New(),
+	}
+
+	t.Run("It should fail a step that does not finish before its timeout", func(t *testing.T) {
+		step := pipeline.NamedStep("slow", func(ctx context.Context, opts pipeline.ActionOpts) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).WithTimeout(10 * time.Millisecond)
+
+		wg := syncutil.NewStepWaitGroup()
+		wg.Add(step, opts)
+		testutil.EnsureError(t, wg.Wait(context.Background()), pipeline.ErrorStepTimeout)
+	})
+
+	t.Run("It should fail a step that ignores its context when the timeout expires, without retrying it", func(t *testing.T) {
+		stopTimeout := syncutil.ActionStopTimeout
+		syncutil.ActionStopTimeout = 10 * time.Millisecond
+		defer func() {
+			syncutil.ActionStopTimeout = stopTimeout
+		}()
+
+		var (
+			done     = make(chan bool)
+			attempts int32
+		)
+		defer close(done)
+
+		step := pipeline.NamedStep("stuck", func(ctx context.Context, opts pipeline.ActionOpts) error {
+			atomic.AddInt32(&attempts, 1)
+			<-done
+			return nil
+		}).WithTimeout(10*time.Millisecond).WithRetry(1, time.Millisecond)
+
+		testutil.EnsureError(t, syncutil.RunStep(context.Background(), step, opts), pipeline.ErrorStepTimeout)
+		if n := atomic.LoadInt32(&attempts); n != 1 {
+			t.Fatalf("Expected 1 attempt but got %d", n)
+		}
+	})
+
+	t.Run("It should wait for an attempt to stop before retrying it", func(t *testing.T) {
+		var running, max int32
+		step := pipeline.NamedStep("slow to stop", func(ctx context.Context, opts pipeline.ActionOpts) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			if n > atomic.LoadInt32(&max) {
+				atomic.StoreInt32(&max, n)
+			}
+
+			<-ctx.Done()
+			time.Sleep(20 * time.Millisecond)
+			return ctx.Err()
+		}).WithTimeout(10*time.Millisecond).WithRetry(1, time.Millisecond)
+
+		testutil.EnsureError(t, syncutil.RunStep(context.Background(), step, opts), pipeline.ErrorStepTimeout)
+		if n := atomic.LoadInt32(&max); n != 1 {
+			t.Fatalf("Expected attempts to never run at the same time, but %d did", n)
+		}
+	})
+
+	t.Run("It should retry a step until it succeeds", func(t *testing.T) {
+		attempts := 0
+		step := pipeline.NamedStep("flaky", func(ctx context.Context, opts pipeline.ActionOpts) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("flaky")
+			}
+			return nil
+		}).WithRetry(2, time.Millisecond)
+
+		if err := syncutil.RunStep(context.Background(), step, opts); err != nil {
+			t.Fatal(err)
+		}
+		if attempts != 3 {
+			t.Fatalf("Expected 3 attempts but got %d", attempts)
+		}
+	})
+
+	t.Run("It should return the last error when a step runs out of retries", func(t *testing.T) {
+		var (
+			attempts = 0
+			errFlaky = errors.New("flaky")
+		)
+
+		step := pipeline.NamedStep("flaky", func(ctx context.Context, opts pipeline.ActionOpts) error {
+			attempts++
+			return errFlaky
+		}).WithRetry(1, time.Millisecond)
+
+		testutil.EnsureError(t, syncutil.RunStep(context.Background(), step, opts), errFlaky)
+		if attempts != 2 {
+			t.Fatalf("Expected 2 attempts but got %d", attempts)
+		}
+	})
+
+	t.Run("It should apply the timeout to every attempt", func(t *testing.T) {
+		var attempts int32
+		step := pipeline.NamedStep("slow", func(ctx context.Context, opts pipeline.ActionOpts) error {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		}).WithTimeout(10*time.Millisecond).WithRetry(1, time.Millisecond)
+
+		if err := syncutil.RunStep(context.Background(), step, opts); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("It should not return the error of a step that allows failures", func(t *testing.T) {
+		step := pipeline.NamedStep("allowed", func(ctx context.Context, opts pipeline.ActionOpts) error {
+			return errors.New("failed")
+		}).AllowFailure()
+
+		if err := syncutil.RunStep(context.Background(), step, opts); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package syncutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/scribe/errors"
+	"github.com/grafana/scribe/pipeline"
+	"github.com/sirupsen/logrus"
+)
+
+// SubPipelineGroup runs sub-pipelines (see pipeline.PipelineTypeSub) in the background, so that the pipelines after them do not wait for them, and tracks them until they are done.
+// Sub-pipelines that depend on other sub-pipelines in the group wait for them to succeed before they start.
+type SubPipelineGroup struct {
+	policy FailurePolicy
+	log    logrus.FieldLogger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// detachedCtx is cancelled once every sub-pipeline that is not detached is done, which stops the detached sub-pipelines.
+	detachedCtx    context.Context
+	cancelDetached context.CancelFunc
+
+	wg         sync.WaitGroup
+	detachedWg sync.WaitGroup
+
+	mutex sync.Mutex
+	errs  *errors.ErrorStack
+	// cause is the error of the first sub-pipeline that failed while nothing was cancelled. With the FailFast policy, it is the error that cancelled everything else.
+	cause  error
+	done   map[int64]chan struct{}
+	failed map[int64]bool
+}
+
+// NewSubPipelineGroup creates a SubPipelineGroup that handles errors using the FailurePolicy.
+// The returned context should be used to run the rest of the pipelines; with the FailFast policy, it is cancelled when a sub-pipeline fails.
+func NewSubPipelineGroup(ctx context.Context, policy FailurePolicy, log logrus.FieldLogger) (*SubPipelineGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	detachedCtx, cancelDetached := context.WithCancel(ctx)
+
+	return &SubPipelineGroup{
+		policy:         policy,
+		log:            log,
+		ctx:            ctx,
+		cancel:         cancel,
+		detachedCtx:    detachedCtx,
+		cancelDetached: cancelDetached,
+		errs:           &errors.ErrorStack{},
+		done:           map[int64]chan struct{}{},
+		failed:         map[int64]bool{},
+	}, ctx
+}
+
+// track records that the pipeline has started, and returns the IDs of its dependencies that are in the group along with the channels that are closed when they are done.
+func (g *SubPipelineGroup) track(p pipeline.Pipeline, done chan struct{}) map[int64]chan struct{} {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	deps := map[int64]chan struct{}{}
+	for _, v := range p.Dependencies {
+		if c, ok := g.done[v.ID]; ok {
+			deps[v.ID] = c
+		}
+	}
+
+	g.done[p.ID] = done
+	return deps
+}
+
+// fail records the error of a sub-pipeline that is not detached. 'cancelled' is true if the sub-pipeline was running when the group was cancelled.
+func (g *SubPipelineGroup) fail(p pipeline.Pipeline, err error, cancelled bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.failed[p.ID] = true
+	if p.Detached {
+		return
+	}
+
+	err = fmt.Errorf("sub-pipeline '%s' failed: %w", p.Name, err)
+	g.errs.Push(err)
+	if g.cause == nil && !cancelled {
+		g.cause = err
+	}
+}
+
+// skip records that the sub-pipeline did not run, so that the sub-pipelines that depend on it do not run either.
+func (g *SubPipelineGroup) skip(p pipeline.Pipeline) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.failed[p.ID] = true
+}
+
+func (g *SubPipelineGroup) hasFailed(id int64) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.failed[id]
+}
+
+// Go starts running the steps of the sub-pipeline in the background with the StepWalkFunc, after the sub-pipelines in the group that it depends on are done.
+// If one of those failed, then the sub-pipeline is not ran.
+func (g *SubPipelineGroup) Go(p pipeline.Pipeline, w pipeline.Walker, wf pipeline.StepWalkFunc) {
+	var (
+		ctx  = g.ctx
+		wg   = &g.wg
+		done = make(chan struct{})
+		log  = g.log.WithField("pipeline", p.Name)
+	)
+
+	if p.Detached {
+		ctx, wg = g.detachedCtx, &g.detachedWg
+	}
+
+	deps := g.track(p, done)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+
+		for id, c := range deps {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c:
+			}
+
+			if g.hasFailed(id) {
+				g.skip(p)
+				log.WithField("status", "skipped").Infoln("not running sub-pipeline, as a sub-pipeline that it depends on failed")
+				return
+			}
+		}
+
+		err := w.WalkSteps(ctx, p.ID, wf)
+		if err == nil {
+			return
+		}
+
+		cancelled := ctx.Err() != nil
+		g.fail(p, err, cancelled)
+
+		switch {
+		case p.Detached && cancelled:
+			log.WithError(err).Debugln("detached sub-pipeline was stopped")
+		case p.Detached:
+			log.WithError(err).Errorln("detached sub-pipeline failed")
+		case g.policy == FailFast:
+			log.WithError(err).Errorln("sub-pipeline failed; cancelling the rest of the pipeline")
+			g.cancel()
+		default:
+			log.WithError(err).Errorln("sub-pipeline failed")
+		}
+	}()
+}
+
+// Join waits for every sub-pipeline that is not detached, then stops the detached sub-pipelines and waits for them to return.
+// 'err' is the error from running the rest of the pipelines. It is combined with the errors of the sub-pipelines using the FailurePolicy:
+// with FailFast, the sub-pipelines are cancelled if 'err' is not nil, and only the error that caused the failure is returned;
+// with ContinueAll, an *errors.ErrorStack with every error is returned.
+func (g *SubPipelineGroup) Join(err error) error {
+	if err != nil && g.policy == FailFast {
+		g.cancel()
+	}
+
+	g.wg.Wait()
+	g.cancelDetached()
+	g.detachedWg.Wait()
+	g.cancel()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if len(g.errs.Errors) == 0 {
+		return err
+	}
+
+	if g.policy == FailFast {
+		// If a sub-pipeline failed first, then 'err' is likely the cancellation that it caused.
+		if g.cause != nil {
+			return g.cause
+		}
+		if err != nil {
+			return err
+		}
+		return g.errs.Errors[0]
+	}
+
+	stack := &errors.ErrorStack{}
+	if err != nil {
+		stack.Push(err)
+	}
+	for _, v := range g.errs.Errors {
+		stack.Push(v)
+	}
+
+	return stack
+}
@@ -0,0 +1,137 @@
+package syncutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/syncutil"
+	"github.com/grafana/scribe/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+// walker runs a function for each pipeline ID instead of walking its steps.
+type walker map[int64]func(context.Context) error
+
+func (w walker) WalkSteps(ctx context.Context, id int64, wf pipeline.StepWalkFunc) error {
+	return w[id](ctx)
+}
+
+func (w walker) WalkPipelines(ctx context.Context, wf pipeline.PipelineWalkFunc) error {
+	return nil
+}
+
+func sub(id int64, deps ...pipeline.Pipeline) pipeline.Pipeline {
+	p := pipeline.New("sub", id)
+	p.Type = pipeline.PipelineTypeSub
+	p.Dependencies = deps
+	return p
+}
+
+func TestSubPipelineGroup(t *testing.T) {
+	var (
+		errA = errors.New("a")
+		errB = errors.New("b")
+		log  = logrus.New()
+	)
+
+	t.Run("Join should wait for the sub-pipelines and return their errors", func(t *testing.T) {
+		finished := false
+		w := walker{
+			1: func(context.Context) error {
+				time.Sleep(10 * time.Millisecond)
+				finished = true
+				return errA
+			},
+		}
+
+		g, _ := syncutil.NewSubPipelineGroup(context.Background(), syncutil.ContinueAll, log)
+		g.Go(sub(1), w, nil)
+
+		err := g.Join(errB)
+		testutil.EnsureError(t, err, errA)
+		testutil.EnsureError(t, err, errB)
+		if !finished {
+			t.Fatal("Expected the sub-pipeline to finish before Join returned")
+		}
+	})
+
+	t.Run("With FailFast, a failed sub-pipeline should cancel the rest of the pipeline", func(t *testing.T) {
+		w := walker{
+			1: func(context.Context) error {
+				return errA
+			},
+		}
+
+		g, ctx := syncutil.NewSubPipelineGroup(context.Background(), syncutil.FailFast, log)
+		g.Go(sub(1), w, nil)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("Expected the context to be cancelled")
+		}
+
+		testutil.EnsureError(t, g.Join(ctx.Err()), errA)
+	})
+
+	t.Run("With FailFast, a failed pipeline should cancel the sub-pipelines", func(t *testing.T) {
+		w := walker{
+			1: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+
+		g, _ := syncutil.NewSubPipelineGroup(context.Background(), syncutil.FailFast, log)
+		g.Go(sub(1), w, nil)
+
+		testutil.EnsureError(t, g.Join(errA), errA)
+	})
+
+	t.Run("Detached sub-pipelines should be stopped, and their errors should not be returned", func(t *testing.T) {
+		w := walker{
+			1: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			2: func(ctx context.Context) error {
+				return errA
+			},
+		}
+
+		g, _ := syncutil.NewSubPipelineGroup(context.Background(), syncutil.FailFast, log)
+		for _, v := range []pipeline.Pipeline{sub(1), sub(2)} {
+			v.Detached = true
+			g.Go(v, w, nil)
+		}
+
+		if err := g.Join(nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Sub-pipelines should not run if a sub-pipeline that they depend on failed", func(t *testing.T) {
+		ran := false
+		w := walker{
+			1: func(ctx context.Context) error {
+				return errA
+			},
+			2: func(ctx context.Context) error {
+				ran = true
+				return nil
+			},
+		}
+
+		g, _ := syncutil.NewSubPipelineGroup(context.Background(), syncutil.ContinueAll, log)
+		g.Go(sub(1), w, nil)
+		g.Go(sub(2, sub(1)), w, nil)
+
+		testutil.EnsureError(t, g.Join(nil), errA)
+		if ran {
+			t.Fatal("Expected the sub-pipeline to not run")
+		}
+	})
+}
@@ -4,57 +4,108 @@ import (
 	"context"
 	"fmt"
 	"sync"
+
+	"github.com/grafana/scribe/errors"
 )
 
 type WaitGroupFunc func(context.Context) error
 
+// FailurePolicy defines what a WaitGroup does when one of its functions returns an error.
+type FailurePolicy int
+
+const (
+	// FailFast cancels the context of the other functions when one of them returns an error, waits for them to return, and returns the first error.
+	// This is the default.
+	FailFast FailurePolicy = iota
+	// ContinueAll waits for every function to return, and returns an *errors.ErrorStack with the error of every function that failed.
+	ContinueAll
+)
+
+func (p FailurePolicy) String() string {
+	if p == ContinueAll {
+		return "continue-all"
+	}
+
+	return "fail-fast"
+}
+
 type WaitGroup struct {
 	funcs []WaitGroupFunc
 
-	wg *sync.WaitGroup
+	wg     *sync.WaitGroup
+	policy FailurePolicy
 }
 
 func (w *WaitGroup) Add(f WaitGroupFunc) {
 	w.funcs = append(w.funcs, f)
 }
 
+// Wait runs every function that was added in parallel and waits for them to return, handling errors using the WaitGroup's FailurePolicy.
+// If the provided context is cancelled, then Wait returns right away without waiting for the functions to return.
 func (w *WaitGroup) Wait(ctx context.Context) error {
 	var (
 		doneChan = make(chan bool)
-		errChan  = make(chan error)
+		// errChan is buffered so that every function can send its error and return, even if Wait has already returned.
+		errChan = make(chan error, len(w.funcs))
 	)
 
+	fctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	w.wg.Add(len(w.funcs))
 
 	for _, v := range w.funcs {
 		go func(f WaitGroupFunc) {
-			if err := f(ctx); err != nil {
+			defer w.wg.Done()
+			if err := f(fctx); err != nil {
 				errChan <- err
+				if w.policy == FailFast {
+					cancel()
+				}
 			}
-
-			w.wg.Done()
 		}(v)
 	}
 
 	go func() {
 		w.wg.Wait()
-		doneChan <- true
+		close(doneChan)
 	}()
 
 	select {
-	case err := <-ctx.Done():
-		return fmt.Errorf("%w: %s", context.Canceled, err)
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %s", context.Canceled, ctx.Err())
 	case <-doneChan:
+	}
+
+	// Every function has returned, so nothing else will be sent.
+	close(errChan)
+
+	stack := &errors.ErrorStack{}
+	for err := range errChan {
+		stack.Push(err)
+	}
+
+	if len(stack.Errors) == 0 {
 		return nil
-	case err := <-errChan:
-		return err
 	}
 
+	// The first error is the one that cancelled the other functions, which likely returned context.Canceled.
+	if w.policy == FailFast {
+		return stack.Errors[0]
+	}
+
+	return stack
 }
 
 func NewWaitGroup() *WaitGroup {
+	return NewWaitGroupWithPolicy(FailFast)
+}
+
+// NewWaitGroupWithPolicy creates a WaitGroup that handles errors using the FailurePolicy.
+func NewWaitGroupWithPolicy(policy FailurePolicy) *WaitGroup {
 	return &WaitGroup{
-		funcs: []WaitGroupFunc{},
-		wg:    &sync.WaitGroup{},
+		funcs:  []WaitGroupFunc{},
+		wg:     &sync.WaitGroup{},
+		policy: policy,
 	}
 }
@@ -0,0 +1,109 @@
+package syncutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	scribeerrors "github.com/grafana/scribe/errors"
+	"github.com/grafana/scribe/syncutil"
+	"github.com/grafana/scribe/testutil"
+)
+
+func TestWaitGroup(t *testing.T) {
+	var (
+		errA = errors.New("a")
+		errB = errors.New("b")
+	)
+
+	t.Run("FailFast should cancel the other functions and return the first error", func(t *testing.T) {
+		var (
+			wg        = syncutil.NewWaitGroupWithPolicy(syncutil.FailFast)
+			cancelled = make(chan bool, 1)
+		)
+
+		wg.Add(func(ctx context.Context) error {
+			return errA
+		})
+		wg.Add(func(ctx context.Context) error {
+			<-ctx.Done()
+			cancelled <- true
+			return ctx.Err()
+		})
+
+		testutil.EnsureError(t, wg.Wait(context.Background()), errA)
+
+		select {
+		case <-cancelled:
+		default:
+			t.Fatal("Expected the other function to be cancelled and to return before Wait returned")
+		}
+	})
+
+	t.Run("ContinueAll should wait for every function and return every error", func(t *testing.T) {
+		var (
+			wg       = syncutil.NewWaitGroupWithPolicy(syncutil.ContinueAll)
+			finished = make(chan bool, 1)
+		)
+
+		wg.Add(func(ctx context.Context) error {
+			return errA
+		})
+		wg.Add(func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(10 * time.Millisecond):
+			}
+
+			finished <- true
+			return errB
+		})
+		wg.Add(func(ctx context.Context) error {
+			return nil
+		})
+
+		err := wg.Wait(context.Background())
+		testutil.EnsureError(t, err, errA)
+		testutil.EnsureError(t, err, errB)
+
+		stack := &scribeerrors.ErrorStack{}
+		if !errors.As(err, &stack) || len(stack.Errors) != 2 {
+			t.Fatalf("Expected an ErrorStack with 2 errors but got '%v'", err)
+		}
+
+		select {
+		case <-finished:
+		default:
+			t.Fatal("Expected the other function to finish without being cancelled")
+		}
+	})
+
+	t.Run("Functions that fail after Wait returned should not block", func(t *testing.T) {
+		var (
+			wg          = syncutil.NewWaitGroup()
+			ctx, cancel = context.WithCancel(context.Background())
+			returned    = make(chan bool)
+		)
+
+		wg.Add(func(context.Context) error {
+			cancel()
+			time.Sleep(10 * time.Millisecond)
+			return errA
+		})
+		wg.Add(func(context.Context) error {
+			defer close(returned)
+			time.Sleep(10 * time.Millisecond)
+			return errB
+		})
+
+		testutil.EnsureError(t, wg.Wait(ctx), context.Canceled)
+
+		select {
+		case <-returned:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the function to return after Wait returned")
+		}
+	})
+}
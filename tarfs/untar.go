@@ -41,6 +41,8 @@ func Untar(path string, r io.Reader) error {
 				// Then there's no more to read
 				return nil
 			}
+
+			return fmt.Errorf("error reading tar header: %w", err)
 		}
 		if header == nil {
 			continue
@@ -67,7 +69,7 @@ func Untar(path string, r io.Reader) error {
 					return err
 				}
 			}
-			f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, header.FileInfo().Mode())
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, header.FileInfo().Mode())
 			if err != nil {
 				return err
 			}
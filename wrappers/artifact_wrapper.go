@@ -0,0 +1,68 @@
+package wrappers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/scribe/pipeline"
+	"github.com/grafana/scribe/pipeline/clients"
+	"github.com/grafana/scribe/plog"
+	"github.com/sirupsen/logrus"
+)
+
+// ArtifactWrapper extracts the input artifacts of a step before it runs and stores its output artifacts in the state after it runs successfully.
+type ArtifactWrapper struct {
+	Opts clients.CommonOpts
+	Log  *logrus.Logger
+}
+
+func (l *ArtifactWrapper) WrapStep(steps ...pipeline.Step) []pipeline.Step {
+	for i := range steps {
+		step := steps[i]
+		action := steps[i].Action
+
+		if steps[i].Action == nil {
+			continue
+		}
+
+		if len(step.Inputs) == 0 && len(step.Outputs) == 0 {
+			continue
+		}
+
+		steps[i].Action = func(ctx context.Context, opts pipeline.ActionOpts) error {
+			log := l.Log.WithFields(plog.DefaultFields(ctx, step, l.Opts))
+			for _, v := range step.Inputs {
+				log.WithField("artifact", v.Name).Debugln("extracting artifact")
+				if err := pipeline.ExtractArtifact(opts.State, v, "."); err != nil {
+					return fmt.Errorf("error extracting input artifact: %w", err)
+				}
+			}
+
+			if err := action(ctx, opts); err != nil {
+				return err
+			}
+
+			for _, v := range step.Outputs {
+				log.WithField("artifact", v.Name).Debugln("storing artifact")
+				if err := pipeline.PackageArtifact(opts.State, v, "."); err != nil {
+					return fmt.Errorf("error storing output artifact: %w", err)
+				}
+			}
+
+			return nil
+		}
+	}
+
+	return steps
+}
+
+func (l *ArtifactWrapper) Wrap(wf pipeline.StepWalkFunc) pipeline.StepWalkFunc {
+	return func(ctx context.Context, step ...pipeline.Step) error {
+		steps := l.WrapStep(step...)
+
+		if err := wf(ctx, steps...); err != nil {
+			return err
+		}
+		return nil
+	}
+}
@@ -20,6 +20,26 @@ func (l *LogWrapper) Fields(ctx context.Context, step pipeline.Step) logrus.Fiel
 	return fields
 }
 
+// conditionOpts returns the values that the conditions of a step are checked against before it runs.
+// When a single step is ran with the '-step' argument, the steps before it ran elsewhere, so their status is not known.
+func (l *LogWrapper) conditionOpts(ctx context.Context, opts pipeline.ActionOpts) pipeline.ConditionOpts {
+	c := pipeline.ConditionOpts{
+		State:  opts.State,
+		Status: pipeline.StatusFromContext(ctx),
+	}
+
+	if l.Opts.Args != nil {
+		c.Event = l.Opts.Args.Event
+		if l.Opts.Args.Step != nil {
+			c.Status = pipeline.StatusUnknown
+		}
+	}
+
+	return c
+}
+
+// WrapStep wraps the action of every step so that the start, end, and any error of the step are logged.
+// Steps whose conditions do not match are skipped instead, and are logged with the 'skipped' status.
 func (l *LogWrapper) WrapStep(steps ...pipeline.Step) []pipeline.Step {
 	for i := range steps {
 		step := steps[i]
@@ -33,6 +53,13 @@ func (l *LogWrapper) WrapStep(steps ...pipeline.Step) []pipeline.Step {
 		}
 
 		steps[i].Action = func(ctx context.Context, opts pipeline.ActionOpts) error {
+			if err := step.Skip(l.conditionOpts(ctx, opts)); err != nil {
+				fields := l.Fields(ctx, step)
+				fields["status"] = "skipped"
+				l.Log.WithFields(fields).Infoln(err.Error())
+				return nil
+			}
+
 			l.Log.WithFields(l.Fields(ctx, step)).Infoln("starting step'")
 
 			stdoutFields := l.Fields(ctx, step)